@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"polcompass/backend/config"
+	"polcompass/backend/models"
+	"text/tabwriter"
+)
+
+// audit runs the audit command, args are what follows "audit".
+func audit(cfg config.Config, args []string) error {
+	if cfg.Features.InMemory {
+		return errors.New("there is nothing to audit with features.in_memory")
+	}
+
+	repair := false
+	if len(args) > 0 {
+		if args[0] != "repair" {
+			return fmt.Errorf("unknown audit action %q, expected repair or nothing", args[0])
+		}
+		repair = true
+	}
+
+	store := models.NewGormStore(openDatabase(cfg.Database))
+	mismatches, err := store.AuditQuestionCounts(context.Background(), repair)
+	if err != nil {
+		return err
+	}
+	if len(mismatches) == 0 {
+		fmt.Println("the question counts are consistent")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "POLCOMPASS\tAXIS\tCOLUMN\tSTORED\tACTUAL")
+	for _, mismatch := range mismatches {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\n", mismatch.PolcompassID, mismatch.Axis, mismatch.Column, mismatch.Stored, mismatch.Actual)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if repair {
+		fmt.Printf("repaired %d question count(s)\n", len(mismatches))
+		return nil
+	}
+	return fmt.Errorf("found %d stale question count(s), run audit repair to fix them", len(mismatches))
+}
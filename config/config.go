@@ -0,0 +1,570 @@
+// Package config loads the settings of the server from an optional TOML file
+// and from environment variables, the environment wins over the file.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+type Config struct {
+	Database DatabaseConfig `toml:"database"`
+	Server   ServerConfig   `toml:"server"`
+	CORS     CORSConfig     `toml:"cors"`
+	Features FeatureConfig  `toml:"features"`
+	Log      LogConfig      `toml:"log"`
+}
+
+type DatabaseConfig struct {
+	// URL picks the database by its scheme: postgres://, mysql:// or sqlite:,
+	// a URL without a scheme is a postgres key=value DSN.
+	URL string `toml:"url"`
+	// SSLMode is appended to postgres URLs not setting one, empty leaves URL untouched.
+	SSLMode         string   `toml:"ssl_mode"`
+	MaxOpenConns    int      `toml:"max_open_conns"`
+	MaxIdleConns    int      `toml:"max_idle_conns"`
+	ConnMaxLifetime Duration `toml:"conn_max_lifetime"`
+	ConnMaxIdleTime Duration `toml:"conn_max_idle_time"`
+	// ConnectTimeout is how long to keep retrying the first connection,
+	// waiting from RetryInitialDelay up to RetryMaxDelay between attempts.
+	ConnectTimeout    Duration `toml:"connect_timeout"`
+	RetryInitialDelay Duration `toml:"retry_initial_delay"`
+	RetryMaxDelay     Duration `toml:"retry_max_delay"`
+	// AutoMigrate applies the pending migrations when the server starts,
+	// disable it to run them with the migrate command instead.
+	AutoMigrate bool `toml:"auto_migrate"`
+	// SlowQueryThreshold logs the queries taking longer as warnings, 0 disables it.
+	SlowQueryThreshold Duration `toml:"slow_query_threshold"`
+}
+
+type LogConfig struct {
+	// Level is one of debug, info, warn or error.
+	Level string `toml:"level"`
+}
+
+type ServerConfig struct {
+	Addr              string   `toml:"addr"`
+	ReadTimeout       Duration `toml:"read_timeout"`
+	ReadHeaderTimeout Duration `toml:"read_header_timeout"`
+	WriteTimeout      Duration `toml:"write_timeout"`
+	IdleTimeout       Duration `toml:"idle_timeout"`
+	// ShutdownTimeout is how long requests in flight may run after SIGTERM,
+	// Heroku kills the process 30 seconds after sending it.
+	ShutdownTimeout Duration `toml:"shutdown_timeout"`
+}
+
+// CORSConfig is the policy answered to browsers calling the API from another
+// origin.
+type CORSConfig struct {
+	// AllowOrigins lists origins like "https://polcompass.example", a pattern
+	// like "https://*.polcompass.example" allows every subdomain and "*" alone
+	// allows any origin.
+	AllowOrigins  []string `toml:"allow_origins"`
+	AllowMethods  []string `toml:"allow_methods"`
+	AllowHeaders  []string `toml:"allow_headers"`
+	ExposeHeaders []string `toml:"expose_headers"`
+	// AllowCredentials lets browsers send cookies and authorization headers,
+	// it cannot be combined with the "*" origin.
+	AllowCredentials bool `toml:"allow_credentials"`
+	// MaxAge is how long browsers may cache the answer to a preflight request.
+	MaxAge Duration `toml:"max_age"`
+}
+
+// FeatureConfig switches optional parts of the API.
+type FeatureConfig struct {
+	// InMemory keeps the polcompasses in memory instead of the database.
+	InMemory bool `toml:"in_memory"`
+	// Responses enables storing and sharing the results of respondents.
+	Responses bool `toml:"responses"`
+	// Metrics serves the Prometheus metrics on /metrics.
+	Metrics bool `toml:"metrics"`
+}
+
+// Duration is a time.Duration written like "30s" or "5m" in the TOML file.
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = duration
+	return nil
+}
+
+var (
+	sslModes    = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	logLevels   = []string{"debug", "info", "warn", "error"}
+	corsMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+)
+
+// Default returns the settings used when neither the file nor the environment sets them.
+func Default() Config {
+	return Config{
+		Database: DatabaseConfig{
+			SSLMode:            "require",
+			MaxOpenConns:       10,
+			MaxIdleConns:       5,
+			ConnMaxLifetime:    Duration{30 * time.Minute},
+			ConnMaxIdleTime:    Duration{5 * time.Minute},
+			ConnectTimeout:     Duration{time.Minute},
+			RetryInitialDelay:  Duration{500 * time.Millisecond},
+			RetryMaxDelay:      Duration{10 * time.Second},
+			AutoMigrate:        true,
+			SlowQueryThreshold: Duration{200 * time.Millisecond},
+		},
+		Server: ServerConfig{
+			Addr:              ":8080",
+			ReadTimeout:       Duration{15 * time.Second},
+			ReadHeaderTimeout: Duration{5 * time.Second},
+			WriteTimeout:      Duration{30 * time.Second},
+			IdleTimeout:       Duration{2 * time.Minute},
+			ShutdownTimeout:   Duration{25 * time.Second},
+		},
+		CORS: CORSConfig{
+			AllowOrigins:  []string{"*"},
+			AllowMethods:  []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowHeaders:  []string{"Origin", "Content-Type", "Authorization", "X-Request-ID"},
+			ExposeHeaders: []string{"Content-Length", "X-Request-ID"},
+			MaxAge:        Duration{12 * time.Hour},
+		},
+		Features: FeatureConfig{Responses: true, Metrics: true},
+		Log:      LogConfig{Level: "info"},
+	}
+}
+
+// Load reads the TOML file at path when path is not empty, applies the
+// environment variables over it and validates the result.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("reading config file: %w", err)
+		}
+		defer file.Close()
+
+		decoder := toml.NewDecoder(file)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&cfg); err != nil {
+			return Config{}, fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// applyEnv overrides the settings with the environment variables that are set.
+func (cfg *Config) applyEnv() error {
+	var errs []error
+
+	setString("DATABASE_URL", &cfg.Database.URL)
+	setString("DATABASE_SSLMODE", &cfg.Database.SSLMode)
+	errs = append(errs,
+		setInt("DATABASE_MAX_OPEN_CONNS", &cfg.Database.MaxOpenConns),
+		setInt("DATABASE_MAX_IDLE_CONNS", &cfg.Database.MaxIdleConns),
+		setDuration("DATABASE_CONN_MAX_LIFETIME", &cfg.Database.ConnMaxLifetime),
+		setDuration("DATABASE_CONN_MAX_IDLE_TIME", &cfg.Database.ConnMaxIdleTime),
+		setDuration("DATABASE_CONNECT_TIMEOUT", &cfg.Database.ConnectTimeout),
+		setDuration("DATABASE_RETRY_INITIAL_DELAY", &cfg.Database.RetryInitialDelay),
+		setDuration("DATABASE_RETRY_MAX_DELAY", &cfg.Database.RetryMaxDelay),
+		setBool("DATABASE_AUTO_MIGRATE", &cfg.Database.AutoMigrate),
+		setDuration("DATABASE_SLOW_QUERY_THRESHOLD", &cfg.Database.SlowQueryThreshold),
+	)
+
+	// LOCAL_MODE predates DATABASE_SSLMODE, local databases run without TLS
+	var localMode bool
+	errs = append(errs, setBool("LOCAL_MODE", &localMode))
+	if localMode {
+		if _, isPresent := lookupEnv("DATABASE_SSLMODE"); !isPresent {
+			cfg.Database.SSLMode = ""
+		}
+	}
+
+	// PORT is the variable set by Heroku and read by gin
+	if port, isPresent := lookupEnv("PORT"); isPresent {
+		cfg.Server.Addr = ":" + port
+	}
+	setString("LISTEN_ADDR", &cfg.Server.Addr)
+	errs = append(errs,
+		setDuration("SERVER_READ_TIMEOUT", &cfg.Server.ReadTimeout),
+		setDuration("SERVER_READ_HEADER_TIMEOUT", &cfg.Server.ReadHeaderTimeout),
+		setDuration("SERVER_WRITE_TIMEOUT", &cfg.Server.WriteTimeout),
+		setDuration("SERVER_IDLE_TIMEOUT", &cfg.Server.IdleTimeout),
+		setDuration("SERVER_SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout),
+	)
+
+	setList("CORS_ALLOW_ORIGINS", &cfg.CORS.AllowOrigins)
+	setList("CORS_ALLOW_METHODS", &cfg.CORS.AllowMethods)
+	setList("CORS_ALLOW_HEADERS", &cfg.CORS.AllowHeaders)
+	setList("CORS_EXPOSE_HEADERS", &cfg.CORS.ExposeHeaders)
+	errs = append(errs,
+		setBool("CORS_ALLOW_CREDENTIALS", &cfg.CORS.AllowCredentials),
+		setDuration("CORS_MAX_AGE", &cfg.CORS.MaxAge),
+		setBool("FEATURE_IN_MEMORY", &cfg.Features.InMemory),
+		setBool("FEATURE_RESPONSES", &cfg.Features.Responses),
+		setBool("FEATURE_METRICS", &cfg.Features.Metrics),
+	)
+
+	setString("LOG_LEVEL", &cfg.Log.Level)
+
+	return errors.Join(errs...)
+}
+
+// Validate reports every invalid setting at once.
+func (cfg Config) Validate() error {
+	var errs []error
+
+	if cfg.Database.URL == "" && !cfg.Features.InMemory {
+		errs = append(errs, errors.New("database.url is required unless features.in_memory is set"))
+	}
+	if cfg.Database.URL != "" && cfg.Database.Dialect() == "" {
+		errs = append(errs, errors.New("database.url must start with postgres://, mysql:// or sqlite:"))
+	}
+	if cfg.Database.SSLMode != "" && !slices.Contains(sslModes, cfg.Database.SSLMode) {
+		errs = append(errs, fmt.Errorf("database.ssl_mode must be one of %s", strings.Join(sslModes, ", ")))
+	}
+	if cfg.Database.MaxOpenConns < 0 {
+		errs = append(errs, errors.New("database.max_open_conns cannot be negative"))
+	}
+	if cfg.Database.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("database.max_idle_conns cannot be negative"))
+	}
+	if cfg.Database.MaxOpenConns > 0 && cfg.Database.MaxIdleConns > cfg.Database.MaxOpenConns {
+		errs = append(errs, errors.New("database.max_idle_conns cannot be greater than database.max_open_conns"))
+	}
+	if cfg.Database.ConnMaxLifetime.Duration < 0 {
+		errs = append(errs, errors.New("database.conn_max_lifetime cannot be negative"))
+	}
+	if cfg.Database.ConnMaxIdleTime.Duration < 0 {
+		errs = append(errs, errors.New("database.conn_max_idle_time cannot be negative"))
+	}
+	if cfg.Database.ConnectTimeout.Duration < 0 {
+		errs = append(errs, errors.New("database.connect_timeout cannot be negative"))
+	}
+	if cfg.Database.RetryInitialDelay.Duration <= 0 {
+		errs = append(errs, errors.New("database.retry_initial_delay must be positive"))
+	}
+	if cfg.Database.RetryMaxDelay.Duration < cfg.Database.RetryInitialDelay.Duration {
+		errs = append(errs, errors.New("database.retry_max_delay cannot be shorter than database.retry_initial_delay"))
+	}
+
+	if cfg.Database.SlowQueryThreshold.Duration < 0 {
+		errs = append(errs, errors.New("database.slow_query_threshold cannot be negative"))
+	}
+
+	if cfg.Server.Addr == "" {
+		errs = append(errs, errors.New("server.addr is required"))
+	}
+	timeouts := []struct {
+		name  string
+		value Duration
+	}{
+		{"server.read_timeout", cfg.Server.ReadTimeout},
+		{"server.read_header_timeout", cfg.Server.ReadHeaderTimeout},
+		{"server.write_timeout", cfg.Server.WriteTimeout},
+		{"server.idle_timeout", cfg.Server.IdleTimeout},
+		{"server.shutdown_timeout", cfg.Server.ShutdownTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value.Duration < 0 {
+			errs = append(errs, fmt.Errorf("%s cannot be negative", timeout.name))
+		}
+	}
+
+	errs = append(errs, cfg.CORS.validate()...)
+
+	if !slices.Contains(logLevels, cfg.Log.Level) {
+		errs = append(errs, fmt.Errorf("log.level must be one of %s", strings.Join(logLevels, ", ")))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (cors CORSConfig) validate() []error {
+	var errs []error
+
+	if len(cors.AllowOrigins) == 0 {
+		errs = append(errs, errors.New("cors.allow_origins cannot be empty, use \"*\" to allow any origin"))
+	}
+	for i, origin := range cors.AllowOrigins {
+		if origin == "*" {
+			if len(cors.AllowOrigins) > 1 {
+				errs = append(errs, errors.New("cors.allow_origins cannot mix \"*\" with other origins"))
+			}
+			if cors.AllowCredentials {
+				errs = append(errs, errors.New("cors.allow_credentials cannot be combined with the \"*\" origin, list the allowed origins instead"))
+			}
+			continue
+		}
+		if err := validateOrigin(origin); err != nil {
+			errs = append(errs, fmt.Errorf("cors.allow_origins[%d] %w", i, err))
+		}
+	}
+
+	if len(cors.AllowMethods) == 0 {
+		errs = append(errs, errors.New("cors.allow_methods cannot be empty"))
+	}
+	for i, method := range cors.AllowMethods {
+		if !slices.Contains(corsMethods, method) {
+			errs = append(errs, fmt.Errorf("cors.allow_methods[%d] must be one of %s", i, strings.Join(corsMethods, ", ")))
+		}
+	}
+	for i, header := range cors.AllowHeaders {
+		if header == "" || header == "*" {
+			errs = append(errs, fmt.Errorf("cors.allow_headers[%d] must be a header name", i))
+		}
+	}
+	for i, header := range cors.ExposeHeaders {
+		if header == "" || header == "*" {
+			errs = append(errs, fmt.Errorf("cors.expose_headers[%d] must be a header name", i))
+		}
+	}
+	if cors.MaxAge.Duration < 0 {
+		errs = append(errs, errors.New("cors.max_age cannot be negative"))
+	}
+
+	return errs
+}
+
+// validateOrigin accepts scheme://host[:port], the host may start with "*."
+// to match its subdomains.
+func validateOrigin(origin string) error {
+	scheme, host, found := strings.Cut(origin, "://")
+	if !found || (scheme != "http" && scheme != "https") {
+		return fmt.Errorf("%q must start with http:// or https://", origin)
+	}
+	if host == "" || strings.ContainsAny(host, "/?#@") {
+		return fmt.Errorf("%q must be a scheme and a host, without a path", origin)
+	}
+	if strings.Contains(strings.TrimPrefix(host, "*."), "*") || strings.TrimPrefix(host, "*.") == "" {
+		return fmt.Errorf("%q may only use a wildcard as its first label, like https://*.example.com", origin)
+	}
+	return nil
+}
+
+// The dialects returned by DatabaseConfig.Dialect.
+const (
+	DialectPostgres = "postgres"
+	DialectMySQL    = "mysql"
+	DialectSQLite   = "sqlite"
+)
+
+// Dialect returns the database picked by the scheme of the URL, or an empty
+// string for an unknown scheme.
+func (d DatabaseConfig) Dialect() string {
+	switch scheme, _, _ := strings.Cut(d.URL, ":"); scheme {
+	case "postgres", "postgresql":
+		return DialectPostgres
+	case "mysql":
+		return DialectMySQL
+	case "sqlite", "sqlite3", "file":
+		return DialectSQLite
+	}
+	if !strings.Contains(d.URL, "://") {
+		// a key=value DSN like "host=localhost dbname=polcompass"
+		return DialectPostgres
+	}
+	return ""
+}
+
+// DSN returns the URL in the form expected by the driver of its dialect.
+// Postgres URLs get the SSL mode unless they already set one, mysql URLs
+// become user:password@tcp(host)/dbname with parseTime enabled and sqlite
+// URLs become a file path, sqlite::memory: being an in-memory database.
+func (d DatabaseConfig) DSN() string {
+	switch d.Dialect() {
+	case DialectMySQL:
+		return mysqlDSN(d.URL)
+	case DialectSQLite:
+		return sqliteDSN(d.URL)
+	}
+	if d.SSLMode == "" || strings.Contains(d.URL, "sslmode=") {
+		return d.URL
+	}
+	if !strings.Contains(d.URL, "://") {
+		return strings.TrimSpace(d.URL + " sslmode=" + d.SSLMode)
+	}
+	separator := "?"
+	if strings.Contains(d.URL, "?") {
+		separator = "&"
+	}
+	return d.URL + separator + "sslmode=" + d.SSLMode
+}
+
+func mysqlDSN(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	query := u.Query()
+	if !query.Has("parseTime") {
+		query.Set("parseTime", "true")
+	}
+
+	var dsn strings.Builder
+	if u.User != nil {
+		dsn.WriteString(u.User.Username())
+		if password, hasPassword := u.User.Password(); hasPassword {
+			dsn.WriteString(":" + password)
+		}
+		dsn.WriteString("@")
+	}
+	if u.Host != "" {
+		dsn.WriteString("tcp(" + u.Host + ")")
+	}
+	dsn.WriteString("/" + strings.TrimPrefix(u.Path, "/") + "?" + query.Encode())
+	return dsn.String()
+}
+
+func sqliteDSN(raw string) string {
+	if strings.HasPrefix(raw, "file:") {
+		return raw
+	}
+	_, path, _ := strings.Cut(raw, ":")
+	path = strings.TrimPrefix(path, "//")
+	if path == ":memory:" {
+		// every connection of the pool must see the same database
+		return "file::memory:?cache=shared"
+	}
+	return path
+}
+
+// Dump writes cfg as TOML, hiding the database password.
+func (cfg Config) Dump() (string, error) {
+	cfg.Database.URL = redactURL(cfg.Database.URL)
+	data, err := toml.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// redactURL hides the database password, whether it is in the user info or
+// a password query parameter of a URL, or a password=... pair of a key=value
+// DSN.
+func redactURL(raw string) string {
+	if !strings.Contains(raw, "://") {
+		return keywordPassword.ReplaceAllString(raw, "${1}xxxxx")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		// the user info cannot be told apart, hide all of it
+		scheme, rest, _ := strings.Cut(raw, "://")
+		if at := strings.LastIndex(rest, "@"); at >= 0 {
+			rest = "xxxxx" + rest[at:]
+		}
+		return scheme + "://" + queryPassword.ReplaceAllString(rest, "${1}xxxxx")
+	}
+	if u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			u.User = url.UserPassword(u.User.Username(), "xxxxx")
+		}
+	}
+	// the query is edited in place, Query().Encode() would reorder it
+	params := strings.Split(u.RawQuery, "&")
+	for i, param := range params {
+		if key, _, _ := strings.Cut(param, "="); strings.EqualFold(key, "password") {
+			params[i] = key + "=xxxxx"
+		}
+	}
+	u.RawQuery = strings.Join(params, "&")
+	return u.String()
+}
+
+// queryPassword matches the password query parameter of a URL.
+var queryPassword = regexp.MustCompile(`(?i)([?&]password=)[^&#]*`)
+
+// keywordPassword matches the password of a key=value DSN, quoted or not.
+var keywordPassword = regexp.MustCompile(`(?i)(\bpassword\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+
+func setString(name string, target *string) {
+	if value, isPresent := lookupEnv(name); isPresent {
+		*target = value
+	}
+}
+
+func setList(name string, target *[]string) {
+	if value, isPresent := lookupEnv(name); isPresent {
+		*target = splitList(value)
+	}
+}
+
+func setInt(name string, target *int) error {
+	value, isPresent := lookupEnv(name)
+	if !isPresent {
+		return nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%s must be an integer", name)
+	}
+	*target = parsed
+	return nil
+}
+
+func setBool(name string, target *bool) error {
+	value, isPresent := lookupEnv(name)
+	if !isPresent {
+		return nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("%s must be true or false", name)
+	}
+	*target = parsed
+	return nil
+}
+
+func setDuration(name string, target *Duration) error {
+	value, isPresent := lookupEnv(name)
+	if !isPresent {
+		return nil
+	}
+	if err := target.UnmarshalText([]byte(value)); err != nil {
+		return fmt.Errorf("%s must be a duration like 30s", name)
+	}
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// lookupEnv treats empty variables as unset, compose files often declare
+// variables without a value.
+func lookupEnv(name string) (string, bool) {
+	value := os.Getenv(name)
+	return value, value != ""
+}
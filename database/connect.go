@@ -0,0 +1,83 @@
+// Package database opens the connection to the database of the server.
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"polcompass/backend/config"
+	"polcompass/backend/logging"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Connect opens dialector, retrying with an exponential backoff until
+// cfg.ConnectTimeout has elapsed, then sizes the connection pool.
+func Connect(ctx context.Context, dialector gorm.Dialector, cfg config.DatabaseConfig) (*gorm.DB, error) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.ConnectTimeout.Duration)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		db, err := gorm.Open(dialector, &gorm.Config{Logger: logging.NewGormLogger(slog.Default(), cfg.SlowQueryThreshold.Duration)})
+		if err == nil {
+			err = configurePool(db, cfg)
+		}
+		if err == nil {
+			slog.Info("connected to the database", "dialect", dialector.Name(), "attempts", attempt)
+			return db, nil
+		}
+		closeQuietly(db)
+
+		delay := Backoff(attempt, cfg.RetryInitialDelay.Duration, cfg.RetryMaxDelay.Duration)
+		slog.Warn("database connection failed", "attempt", attempt, "error", err.Error(), "retry_in", delay.Round(time.Millisecond).String())
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("could not connect to the database after %d attempt(s): %w", attempt, err)
+		case <-time.After(delay):
+		}
+	}
+}
+
+// Backoff returns how long to wait after the given failed attempt, counting
+// from 1. The delay doubles on each attempt up to maxDelay, and a random
+// half of it is dropped so restarted replicas do not retry in lockstep.
+func Backoff(attempt int, initialDelay time.Duration, maxDelay time.Duration) time.Duration {
+	delay := initialDelay
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+func configurePool(db *gorm.DB, cfg config.DatabaseConfig) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime.Duration)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime.Duration)
+	return nil
+}
+
+// closeQuietly releases the pool of a failed attempt.
+func closeQuietly(db *gorm.DB) {
+	if db == nil {
+		return
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
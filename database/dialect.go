@@ -0,0 +1,27 @@
+package database
+
+import (
+	"fmt"
+	"polcompass/backend/config"
+	"strings"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Dialector returns the gorm dialector of the database picked by the scheme
+// of cfg.URL.
+func Dialector(cfg config.DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Dialect() {
+	case config.DialectPostgres:
+		return postgres.Open(cfg.DSN()), nil
+	case config.DialectMySQL:
+		return mysql.Open(cfg.DSN()), nil
+	case config.DialectSQLite:
+		return sqlite.Open(cfg.DSN()), nil
+	}
+	scheme, _, _ := strings.Cut(cfg.URL, ":")
+	return nil, fmt.Errorf("unsupported database url scheme %q", scheme)
+}
@@ -0,0 +1,122 @@
+// Package health serves the liveness and readiness probes of the server.
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"polcompass/backend/migrations"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Check tells whether a dependency of the server works. Run returns the
+// details to report, and an error when the dependency is not usable.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (map[string]interface{}, error)
+}
+
+type Handler struct {
+	Checks []Check
+	// Timeout bounds how long all the checks of one probe may take.
+	Timeout time.Duration
+}
+
+func NewHandler(checks ...Check) *Handler {
+	return &Handler{Checks: checks, Timeout: 2 * time.Second}
+}
+
+// DependencyStatus is the result of one Check.
+type DependencyStatus struct {
+	Status    string                 `json:"status"`
+	LatencyMs float64                `json:"latency_ms"`
+	Error     string                 `json:"error,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+type ReadinessResponse struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// Healthz answers as long as the process is able to serve requests.
+func (h *Handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": StatusOK})
+}
+
+// Readyz runs every check and answers 503 when one of them fails.
+func (h *Handler) Readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.Timeout)
+	defer cancel()
+
+	response := ReadinessResponse{Status: StatusOK, Dependencies: make(map[string]DependencyStatus, len(h.Checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range h.Checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+
+			start := time.Now()
+			details, err := check.Run(ctx)
+			status := DependencyStatus{Status: StatusOK, LatencyMs: float64(time.Since(start).Microseconds()) / 1000, Details: details}
+			if err != nil {
+				status.Status = StatusUnavailable
+				status.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			response.Dependencies[check.Name] = status
+			if err != nil {
+				response.Status = StatusUnavailable
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	code := http.StatusOK
+	if response.Status != StatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, response)
+}
+
+// DatabaseCheck pings the database.
+func DatabaseCheck(db *gorm.DB) Check {
+	return Check{Name: "database", Run: func(ctx context.Context) (map[string]interface{}, error) {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		return nil, sqlDB.PingContext(ctx)
+	}}
+}
+
+// MigrationsCheck fails until the schema reached the version this build
+// expects. A newer schema is accepted, instances still running the previous
+// build keep serving while a deploy migrates.
+func MigrationsCheck(db *gorm.DB) Check {
+	return Check{Name: "migrations", Run: func(ctx context.Context) (map[string]interface{}, error) {
+		migrator := migrations.New(db.WithContext(ctx))
+		version, err := migrator.Version()
+		if err != nil {
+			return nil, err
+		}
+		details := map[string]interface{}{"version": version, "expected": migrator.Latest()}
+		if version < migrator.Latest() {
+			return details, fmt.Errorf("the schema is at version %d, %d is expected", version, migrator.Latest())
+		}
+		return details, nil
+	}}
+}
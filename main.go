@@ -1,40 +1,107 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"polcompass/backend/config"
+	"polcompass/backend/database"
+	"polcompass/backend/health"
+	"polcompass/backend/logging"
+	"polcompass/backend/metrics"
+	"polcompass/backend/migrations"
 	"polcompass/backend/models"
-	"strings"
-	"time"
+	"polcompass/backend/server"
+	"syscall"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 func main() {
-	url := os.Getenv("DATABASE_URL")
-	isLocalMode := os.Getenv("LOCAL_MODE")
-
-	time.Sleep(2 * time.Second)
-
-	dsn := url
-	if !strings.Contains(dsn, "sslmode=") && isLocalMode != "true" {
-		dsn += "?sslmode=require"
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "optional TOML configuration file, environment variables take precedence")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-config file] [serve|config|migrate|audit]\n\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "  serve                 run the API (default)")
+		fmt.Fprintln(flag.CommandLine.Output(), "  config                print the effective configuration")
+		fmt.Fprintln(flag.CommandLine.Output(), "  migrate [up]          apply the pending migrations")
+		fmt.Fprintln(flag.CommandLine.Output(), "  migrate down [steps]  revert the last migrations, one by default")
+		fmt.Fprintln(flag.CommandLine.Output(), "  migrate status        list the migrations and whether they were applied")
+		fmt.Fprintln(flag.CommandLine.Output(), "  audit                 list the stored question counts disagreeing with the questions")
+		fmt.Fprintln(flag.CommandLine.Output(), "  audit repair          rewrite the stale question counts")
+		fmt.Fprintln(flag.CommandLine.Output())
+		flag.PrintDefaults()
 	}
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	flag.Parse()
 
+	cfg, err := config.Load(*configPath)
 	if err != nil {
-		panic("failed to connect database")
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+	logger := logging.New(os.Stdout, cfg.Log.Level)
+	slog.SetDefault(logger)
+
+	switch flag.Arg(0) {
+	case "", "serve":
+		serve(cfg, logger)
+	case "config":
+		dump, err := cfg.Dump()
+		if err != nil {
+			fatal("failed to print the configuration", err)
+		}
+		fmt.Print(dump)
+	case "migrate":
+		if err := migrate(cfg, flag.Args()[1:]); err != nil {
+			fatal("migrate failed", err)
+		}
+	case "audit":
+		if err := audit(cfg, flag.Args()[1:]); err != nil {
+			fatal("audit failed", err)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func serve(cfg config.Config, logger *slog.Logger) {
+	var db *gorm.DB
+	store := models.CompassStore(models.NewMemoryStore())
+	healthHandler := health.NewHandler()
+	if !cfg.Features.InMemory {
+		db = openDatabase(cfg.Database)
+		if cfg.Database.AutoMigrate {
+			if _, err := migrations.New(db).Up(); err != nil {
+				fatal("failed to migrate the database", err)
+			}
+		}
+		store = models.NewGormStore(db)
+		healthHandler = health.NewHandler(health.DatabaseCheck(db), health.MigrationsCheck(db))
 	}
 
-	// Migrate the schema
-	db.AutoMigrate(&models.Polcompass{})
-	db.AutoMigrate(&models.Question{})
+	router := gin.New()
 
-	router := gin.Default()
+	router.Use(logging.RequestIDMiddleware(), logging.AccessLog(logger), models.Recovery())
+
+	if cfg.Features.Metrics {
+		m := metrics.New()
+		if db != nil {
+			if sqlDB, err := db.DB(); err == nil {
+				m.RegisterDB(sqlDB)
+			}
+		}
+		store = m.InstrumentStore(store)
+
+		router.Use(m.Middleware())
+
+		router.GET("/metrics", m.Handler())
+	}
 
 	router.GET("/ping", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
@@ -42,16 +109,13 @@ func main() {
 		})
 	})
 
-	// Add CORS middleware
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:5173", "*"}, // Replace with your frontend domains
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-	}))
+	router.GET("/healthz", healthHandler.Healthz)
+
+	router.GET("/readyz", healthHandler.Readyz)
 
-	polCompassController := &models.PolCompassController{DB: db}
+	router.Use(server.CORS(cfg.CORS))
+
+	polCompassController := models.NewPolCompassController(store)
 
 	router.POST("/polcompass", polCompassController.POST)
 
@@ -59,7 +123,69 @@ func main() {
 
 	router.GET("/polcompass/first", polCompassController.First)
 
+	router.GET("/polcompass/:id", polCompassController.GET)
+
+	router.PUT("/polcompass/:id", polCompassController.PUT)
+
+	router.PATCH("/polcompass/:id", polCompassController.PATCH)
+
+	router.DELETE("/polcompass/:id", polCompassController.DELETE)
+
+	router.POST("/polcompass/:id/restore", polCompassController.Restore)
+
+	router.POST("/polcompass/:id/score", polCompassController.Score)
+
+	router.POST("/polcompass/:id/match", polCompassController.Match)
+
+	router.GET("/polcompass/:id/references", polCompassController.ListReferences)
+
+	router.POST("/polcompass/:id/references", polCompassController.CreateReference)
+
+	router.GET("/polcompass/:id/references/:referenceId", polCompassController.GetReference)
+
+	router.PUT("/polcompass/:id/references/:referenceId", polCompassController.UpdateReference)
+
+	router.DELETE("/polcompass/:id/references/:referenceId", polCompassController.DeleteReference)
+
+	if cfg.Features.Responses {
+		router.POST("/polcompass/:id/responses", polCompassController.Submit)
+
+		router.GET("/responses/:publicId", polCompassController.GetResponse)
+	}
+
 	router.GET("/summary", polCompassController.Summary)
 
-	router.Run() // listen and serve on 0.0.0.0:8080
+	// Heroku sends SIGTERM on every deploy, let the requests in flight finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := server.Run(ctx, server.New(router, cfg.Server), cfg.Server)
+
+	if db != nil {
+		if sqlDB, dbErr := db.DB(); dbErr == nil {
+			sqlDB.Close()
+		}
+	}
+
+	if err != nil {
+		fatal("server stopped", err)
+	}
+	slog.Info("server stopped")
+}
+
+func openDatabase(cfg config.DatabaseConfig) *gorm.DB {
+	dialector, err := database.Dialector(cfg)
+	if err != nil {
+		fatal("failed to connect database", err)
+	}
+	db, err := database.Connect(context.Background(), dialector, cfg)
+	if err != nil {
+		fatal("failed to connect database", err)
+	}
+	return db
+}
+
+func fatal(message string, err error) {
+	slog.Error(message, "error", err.Error())
+	os.Exit(1)
 }
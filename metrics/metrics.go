@@ -0,0 +1,129 @@
+// Package metrics exposes the activity of the server in the Prometheus text format.
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"polcompass/backend/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatchedRoute labels the requests no route matched, so scanners hitting
+// random urls cannot create a series per url.
+const unmatchedRoute = "unmatched"
+
+type Metrics struct {
+	Registry          *prometheus.Registry
+	requests          *prometheus.CounterVec
+	duration          *prometheus.HistogramVec
+	compassesCreated  prometheus.Counter
+	questionsUpserted prometheus.Counter
+	responsesSaved    prometheus.Counter
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests by route, method and status code.",
+		}, []string{"method", "route", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Time spent answering HTTP requests by route and method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		compassesCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "polcompass_compasses_created_total",
+			Help: "Polcompasses created.",
+		}),
+		questionsUpserted: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "polcompass_questions_upserted_total",
+			Help: "Questions saved while creating or updating polcompasses.",
+		}),
+		responsesSaved: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "polcompass_responses_saved_total",
+			Help: "Responses of respondents saved.",
+		}),
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.duration,
+		m.compassesCreated,
+		m.questionsUpserted,
+		m.responsesSaved,
+	)
+	return m
+}
+
+// Middleware counts and times every request, labeled by its route pattern.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.requests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.duration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler serves the metrics.
+func (m *Metrics) Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{}))
+}
+
+// RegisterDB exposes the connection pool statistics of db.
+func (m *Metrics) RegisterDB(db *sql.DB) {
+	m.Registry.MustRegister(collectors.NewDBStatsCollector(db, "polcompass"))
+}
+
+// InstrumentStore returns store counting the polcompasses, questions and
+// responses it saves.
+func (m *Metrics) InstrumentStore(store models.CompassStore) models.CompassStore {
+	return &instrumentedStore{CompassStore: store, metrics: m}
+}
+
+type instrumentedStore struct {
+	models.CompassStore
+	metrics *Metrics
+}
+
+func (s *instrumentedStore) Create(ctx context.Context, polcompass *models.Polcompass) error {
+	questions := len(polcompass.Questions)
+	if err := s.CompassStore.Create(ctx, polcompass); err != nil {
+		return err
+	}
+	s.metrics.compassesCreated.Inc()
+	s.metrics.questionsUpserted.Add(float64(questions))
+	return nil
+}
+
+func (s *instrumentedStore) Update(ctx context.Context, polcompass *models.Polcompass, renamed map[string]string) error {
+	questions := len(polcompass.Questions)
+	if err := s.CompassStore.Update(ctx, polcompass, renamed); err != nil {
+		return err
+	}
+	s.metrics.questionsUpserted.Add(float64(questions))
+	return nil
+}
+
+func (s *instrumentedStore) CreateResponse(ctx context.Context, response *models.Response) error {
+	if err := s.CompassStore.CreateResponse(ctx, response); err != nil {
+		return err
+	}
+	s.metrics.responsesSaved.Inc()
+	return nil
+}
@@ -0,0 +1,237 @@
+// Package migrations versions the database schema. Every Migration is applied
+// once, in order, and recorded in the schema_migrations table.
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is one step of the schema, Down undoes what Up did.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration is the row recording an applied Migration.
+type SchemaMigration struct {
+	Version   uint   `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"size:255"`
+	AppliedAt time.Time
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status tells whether a Migration was applied.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	DB         *gorm.DB
+	Migrations []Migration
+}
+
+// New returns a Migrator running the migrations of the server.
+func New(db *gorm.DB) *Migrator {
+	return &Migrator{DB: db, Migrations: All}
+}
+
+// Latest returns the version the schema has once every migration is applied.
+func (m *Migrator) Latest() uint {
+	var latest uint
+	for _, migration := range m.Migrations {
+		latest = max(latest, migration.Version)
+	}
+	return latest
+}
+
+// Version returns the highest applied version, 0 when nothing was applied.
+func (m *Migrator) Version() (uint, error) {
+	if !m.DB.Migrator().HasTable(&SchemaMigration{}) {
+		return 0, nil
+	}
+	var version uint
+	err := m.DB.Model(&SchemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+// Up applies every pending migration and returns them.
+func (m *Migrator) Up() ([]Migration, error) {
+	migrations, err := m.sorted()
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	err = m.withLock(func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if _, isPresent := done[migration.Version]; isPresent {
+				continue
+			}
+
+			slog.Info("applying migration", "version", migration.Version, "name", migration.Name)
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := migration.Up(tx); err != nil {
+					return err
+				}
+				return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations and returns them.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	migrations, err := m.sorted()
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	err = m.withLock(func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := migrations[i]
+			if _, isPresent := done[migration.Version]; !isPresent {
+				continue
+			}
+			if migration.Down == nil {
+				return fmt.Errorf("migration %d %s cannot be reverted", migration.Version, migration.Name)
+			}
+
+			slog.Info("reverting migration", "version", migration.Version, "name", migration.Name)
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := migration.Down(tx); err != nil {
+					return err
+				}
+				return tx.Delete(&SchemaMigration{}, migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status lists every migration, telling which ones were applied.
+func (m *Migrator) Status() ([]Status, error) {
+	migrations, err := m.sorted()
+	if err != nil {
+		return nil, err
+	}
+
+	done := map[uint]SchemaMigration{}
+	if m.DB.Migrator().HasTable(&SchemaMigration{}) {
+		var rows []SchemaMigration
+		if err := m.DB.Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			done[row.Version] = row
+		}
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	for _, migration := range migrations {
+		row, isPresent := done[migration.Version]
+		statuses = append(statuses, Status{Migration: migration, Applied: isPresent, AppliedAt: row.AppliedAt})
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) sorted() ([]Migration, error) {
+	migrations := make([]Migration, len(m.Migrations))
+	copy(migrations, m.Migrations)
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	for i, migration := range migrations {
+		if migration.Version == 0 || migration.Up == nil {
+			return nil, fmt.Errorf("migration %q needs a version and an Up step", migration.Name)
+		}
+		if i > 0 && migrations[i-1].Version == migration.Version {
+			return nil, fmt.Errorf("migrations %q and %q share the version %d", migrations[i-1].Name, migration.Name, migration.Version)
+		}
+	}
+	return migrations, nil
+}
+
+// appliedVersions creates the schema_migrations table when needed and
+// returns the versions it holds.
+func appliedVersions(conn *gorm.DB) (map[uint]bool, error) {
+	if err := conn.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+	var versions []uint
+	if err := conn.Model(&SchemaMigration{}).Pluck("version", &versions).Error; err != nil {
+		return nil, err
+	}
+	done := make(map[uint]bool, len(versions))
+	for _, version := range versions {
+		done[version] = true
+	}
+	return done, nil
+}
+
+// lockID identifies the migration lock, any constant shared by every instance works.
+const lockID = 7_307_811_424
+
+// withLock runs fn on a single connection holding a database wide lock, so
+// instances booting together do not migrate concurrently.
+func (m *Migrator) withLock(fn func(conn *gorm.DB) error) error {
+	return m.DB.Connection(func(conn *gorm.DB) error {
+		// a fresh session so each statement below starts from a clean state
+		conn = conn.Session(&gorm.Session{NewDB: true})
+
+		var lock, unlock string
+		switch conn.Dialector.Name() {
+		case "postgres":
+			lock, unlock = "SELECT pg_advisory_lock(?)", "SELECT pg_advisory_unlock(?)"
+		case "mysql":
+			lock, unlock = "SELECT GET_LOCK(CONCAT('polcompass_migrations_', ?), -1)", "SELECT RELEASE_LOCK(CONCAT('polcompass_migrations_', ?))"
+		default:
+			// sqlite only has one writer at a time
+			return fn(conn)
+		}
+
+		if err := conn.Exec(lock, lockID).Error; err != nil {
+			return fmt.Errorf("taking the migration lock: %w", err)
+		}
+		err := fn(conn)
+		if unlockErr := conn.Exec(unlock, lockID).Error; unlockErr != nil {
+			err = errors.Join(err, fmt.Errorf("releasing the migration lock: %w", unlockErr))
+		}
+		return err
+	})
+}
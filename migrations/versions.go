@@ -0,0 +1,381 @@
+package migrations
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// All lists the migrations of the server. Each one declares the tables as they
+// were at its version, so later changes to the models do not rewrite history.
+// The first steps match the schema AutoMigrate used to create, databases
+// created that way are picked up without errors.
+var All = []Migration{
+	{
+		Version: 1,
+		Name:    "create_polcompasses_and_questions",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&polcompassV1{}, &questionV1{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&questionV1{}, &polcompassV1{})
+		},
+	},
+	{
+		Version: 2,
+		Name:    "create_responses_and_answers",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&responseV2{}, &answerV2{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&answerV2{}, &responseV2{})
+		},
+	},
+	{
+		Version: 3,
+		Name:    "create_axes",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&polcompassV3{}, &axisV3{}); err != nil {
+				return err
+			}
+			return backfillAxes(tx)
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&axisV3{})
+		},
+	},
+	{
+		Version: 4,
+		Name:    "add_question_effects",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&questionV4{}, "Effects") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&questionV4{}, "Effects")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&questionV4{}, "Effects")
+		},
+	},
+	{
+		Version: 5,
+		Name:    "add_answer_scales",
+		Up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&polcompassV5{}, "Scale") {
+				if err := tx.Migrator().AddColumn(&polcompassV5{}, "Scale"); err != nil {
+					return err
+				}
+			}
+			if tx.Migrator().HasColumn(&answerV5{}, "Skipped") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&answerV5{}, "Skipped")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&answerV5{}, "Skipped"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&polcompassV5{}, "Scale")
+		},
+	},
+	{
+		Version: 6,
+		Name:    "add_polcompass_regions",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&polcompassV6{}, "Regions") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&polcompassV6{}, "Regions")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&polcompassV6{}, "Regions")
+		},
+	},
+	{
+		Version: 7,
+		Name:    "create_reference_points",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&polcompassV7{}, &referencePointV7{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&referencePointV7{})
+		},
+	},
+	{
+		Version: 8,
+		Name:    "create_reference_answers",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&referencePointV8{}, &referenceAnswerV8{}); err != nil {
+				return err
+			}
+			if !tx.Migrator().HasColumn(&referencePointV7{}, "Answers") {
+				return nil
+			}
+			if err := moveReferenceAnswers(tx); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropColumn(&referencePointV7{}, "Answers"); err != nil {
+				return err
+			}
+			// sqlite drops a column by copying the table, without its indexes
+			if tx.Migrator().HasIndex(&referencePointV7{}, "PolcompassID") {
+				return nil
+			}
+			return tx.Migrator().CreateIndex(&referencePointV7{}, "PolcompassID")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&referencePointV7{}, "Answers"); err != nil {
+				return err
+			}
+			if err := restoreReferenceAnswers(tx); err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable(&referenceAnswerV8{})
+		},
+	},
+}
+
+type polcompassV1 struct {
+	gorm.Model
+	Field1Name        string
+	Field2Name        string
+	Field1QuestionQty int
+	Field2QuestionQty int
+	Name              string
+	Description       string
+	Questions         []questionV1 `gorm:"foreignKey:PolcompassID"`
+}
+
+func (polcompassV1) TableName() string { return "polcompasses" }
+
+type questionV1 struct {
+	ID           uint   `gorm:"primaryKey"`
+	Question     string `gorm:"column:question;uniqueIndex:idx_polcompass_question,length:191"`
+	Affects      string
+	Direction    int
+	PolcompassID uint `gorm:"uniqueIndex:idx_polcompass_question"`
+}
+
+func (questionV1) TableName() string { return "questions" }
+
+type responseV2 struct {
+	ID           uint   `gorm:"primaryKey"`
+	PublicID     string `gorm:"uniqueIndex;size:32"`
+	CreatedAt    time.Time
+	PolcompassID uint         `gorm:"index"`
+	Polcompass   polcompassV1 `gorm:"foreignKey:PolcompassID"`
+	Field1       float64
+	Field2       float64
+	Scores       string
+	Answers      []answerV2 `gorm:"foreignKey:ResponseID"`
+}
+
+func (responseV2) TableName() string { return "responses" }
+
+type answerV2 struct {
+	ID         uint        `gorm:"primaryKey"`
+	ResponseID uint        `gorm:"index"`
+	QuestionID *uint       `gorm:"index"`
+	Question   *questionV1 `gorm:"foreignKey:QuestionID;constraint:OnDelete:SET NULL"`
+	Value      int
+}
+
+func (answerV2) TableName() string { return "answers" }
+
+type axisV3 struct {
+	ID            uint `gorm:"primaryKey"`
+	PolcompassID  uint `gorm:"uniqueIndex:idx_polcompass_axis"`
+	Position      int
+	Name          string `gorm:"uniqueIndex:idx_polcompass_axis,length:191"`
+	NegativeLabel string
+	PositiveLabel string
+	QuestionQty   int
+}
+
+func (axisV3) TableName() string { return "axes" }
+
+type polcompassV3 struct {
+	gorm.Model
+	Field1Name        string
+	Field2Name        string
+	Field1QuestionQty int
+	Field2QuestionQty int
+	Name              string
+	Description       string
+	Axes              []axisV3 `gorm:"foreignKey:PolcompassID"`
+}
+
+func (polcompassV3) TableName() string { return "polcompasses" }
+
+type questionV4 struct {
+	ID           uint   `gorm:"primaryKey"`
+	Question     string `gorm:"column:question;uniqueIndex:idx_polcompass_question,length:191"`
+	Affects      string
+	Direction    int
+	Effects      string
+	PolcompassID uint `gorm:"uniqueIndex:idx_polcompass_question"`
+}
+
+func (questionV4) TableName() string { return "questions" }
+
+type polcompassV5 struct {
+	ID    uint `gorm:"primaryKey"`
+	Scale string
+}
+
+func (polcompassV5) TableName() string { return "polcompasses" }
+
+type answerV5 struct {
+	ID      uint `gorm:"primaryKey"`
+	Skipped bool
+}
+
+func (answerV5) TableName() string { return "answers" }
+
+type polcompassV6 struct {
+	ID      uint `gorm:"primaryKey"`
+	Regions string
+}
+
+func (polcompassV6) TableName() string { return "polcompasses" }
+
+type polcompassV7 struct {
+	ID         uint               `gorm:"primaryKey"`
+	References []referencePointV7 `gorm:"foreignKey:PolcompassID"`
+}
+
+func (polcompassV7) TableName() string { return "polcompasses" }
+
+type referencePointV7 struct {
+	ID           uint `gorm:"primaryKey"`
+	PolcompassID uint `gorm:"index"`
+	Name         string
+	Description  string
+	Source       string
+	Field1       float64
+	Field2       float64
+	Scores       string
+	Answers      string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (referencePointV7) TableName() string { return "reference_points" }
+
+type referencePointV8 struct {
+	ID      uint                `gorm:"primaryKey"`
+	Answers []referenceAnswerV8 `gorm:"foreignKey:ReferencePointID;constraint:OnDelete:CASCADE"`
+}
+
+func (referencePointV8) TableName() string { return "reference_points" }
+
+type referenceAnswerV8 struct {
+	ID               uint        `gorm:"primaryKey"`
+	ReferencePointID uint        `gorm:"index"`
+	QuestionID       uint        `gorm:"index"`
+	Question         *questionV4 `gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE"`
+	Value            int
+	Skipped          bool
+}
+
+func (referenceAnswerV8) TableName() string { return "reference_answers" }
+
+// answerV7 is an answer of the json answers column of reference_points.
+type answerV7 struct {
+	QuestionID uint `json:"question_id"`
+	Value      int  `json:"value"`
+	Skipped    bool `json:"skipped"`
+}
+
+// moveReferenceAnswers copies the json answers of the reference points to
+// reference_answers, the answers to deleted questions are dropped.
+func moveReferenceAnswers(tx *gorm.DB) error {
+	var references []referencePointV7
+	if err := tx.Where("answers IS NOT NULL AND answers <> ?", "").Find(&references).Error; err != nil {
+		return err
+	}
+
+	for _, reference := range references {
+		var answers []answerV7
+		if err := json.Unmarshal([]byte(reference.Answers), &answers); err != nil {
+			return fmt.Errorf("reference point %d: %w", reference.ID, err)
+		}
+
+		var rows []referenceAnswerV8
+		for _, a := range answers {
+			var count int64
+			if err := tx.Model(&questionV4{}).Where("id = ? AND polcompass_id = ?", a.QuestionID, reference.PolcompassID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				rows = append(rows, referenceAnswerV8{ReferencePointID: reference.ID, QuestionID: a.QuestionID, Value: a.Value, Skipped: a.Skipped})
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		if err := tx.Omit("Question").Create(&rows).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreReferenceAnswers writes the rows of reference_answers back to the
+// json answers column.
+func restoreReferenceAnswers(tx *gorm.DB) error {
+	var rows []referenceAnswerV8
+	if err := tx.Order("id").Find(&rows).Error; err != nil {
+		return err
+	}
+
+	byReference := make(map[uint][]answerV7)
+	var ids []uint
+	for _, row := range rows {
+		if _, isPresent := byReference[row.ReferencePointID]; !isPresent {
+			ids = append(ids, row.ReferencePointID)
+		}
+		byReference[row.ReferencePointID] = append(byReference[row.ReferencePointID], answerV7{QuestionID: row.QuestionID, Value: row.Value, Skipped: row.Skipped})
+	}
+
+	for _, id := range ids {
+		data, err := json.Marshal(byReference[id])
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&referencePointV7{ID: id}).UpdateColumn("answers", string(data)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillAxes creates the axes of polcompasses saved before axes were
+// stored in their own table, from their Field1/Field2 columns.
+func backfillAxes(tx *gorm.DB) error {
+	var polcompasses []polcompassV1
+	err := tx.Unscoped().Where("NOT EXISTS (SELECT 1 FROM axes WHERE axes.polcompass_id = polcompasses.id)").Find(&polcompasses).Error
+	if err != nil {
+		return err
+	}
+
+	for _, polcompass := range polcompasses {
+		var axes []axisV3
+		if polcompass.Field1Name != "" {
+			axes = append(axes, axisV3{PolcompassID: polcompass.ID, Position: 0, Name: polcompass.Field1Name, QuestionQty: polcompass.Field1QuestionQty})
+		}
+		if polcompass.Field2Name != "" && polcompass.Field2Name != polcompass.Field1Name {
+			axes = append(axes, axisV3{PolcompassID: polcompass.ID, Position: 1, Name: polcompass.Field2Name, QuestionQty: polcompass.Field2QuestionQty})
+		}
+		if len(axes) == 0 {
+			continue
+		}
+		if err := tx.Create(&axes).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
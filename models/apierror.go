@@ -0,0 +1,91 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"polcompass/backend/logging"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Stable error codes, clients should match on these instead of the messages.
+const (
+	CodeInvalidBody       = "invalid_body"
+	CodeValidationFailed  = "validation_failed"
+	CodeMissingParameter  = "missing_parameter"
+	CodeInvalidParameter  = "invalid_parameter"
+	CodeInvalidID         = "invalid_id"
+	CodeUnknownAxis       = "unknown_axis"
+	CodeInvalidAxes       = "invalid_axes"
+	CodeInvalidAnswer     = "invalid_answer"
+	CodeCompassNotFound   = "compass_not_found"
+	CodeCompassDeleted    = "compass_deleted"
+	CodeResponseNotFound  = "response_not_found"
+	CodeReferenceNotFound = "reference_not_found"
+	CodeInternal          = "internal_error"
+)
+
+// APIError is the error body of every handler, rendered as application/problem+json.
+type APIError struct {
+	Status  int         `json:"status"`
+	Code    string      `json:"code"`
+	Title   string      `json:"title"`
+	Message string      `json:"detail"`
+	Details interface{} `json:"details,omitempty"`
+	// RequestID repeats the X-Request-ID header, to quote when reporting a problem
+	RequestID string `json:"request_id,omitempty"`
+}
+
+func NewAPIError(status int, code string, message string) *APIError {
+	return &APIError{Status: status, Code: code, Title: http.StatusText(status), Message: message}
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// WithDetails returns a copy of the error carrying machine-readable details.
+func (e *APIError) WithDetails(details interface{}) *APIError {
+	withDetails := *e
+	withDetails.Details = details
+	return &withDetails
+}
+
+func badRequest(code string, message string) *APIError {
+	return NewAPIError(http.StatusBadRequest, code, message)
+}
+
+func notFound(code string, message string) *APIError {
+	return NewAPIError(http.StatusNotFound, code, message)
+}
+
+func internalError(message string) *APIError {
+	return NewAPIError(http.StatusInternalServerError, CodeInternal, message)
+}
+
+// respondError aborts the request with err, errors that are not an APIError
+// are reported as internal errors without leaking their message.
+func respondError(c *gin.Context, err error) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		apiErr = internalError("An unexpected error occurred")
+	}
+	withRequestID := *apiErr
+	withRequestID.RequestID = logging.RequestID(c.Request.Context())
+
+	c.Header("Content-Type", "application/problem+json")
+	c.AbortWithStatusJSON(withRequestID.Status, withRequestID)
+}
+
+// Recovery answers a 500 problem to requests whose handler panicked and logs
+// the panic with its stack.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		slog.ErrorContext(c.Request.Context(), "panic while serving the request", "panic", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+		respondError(c, nil)
+	})
+}
@@ -0,0 +1,193 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Axis is one dimension of a polcompass. A positive Direction on a question
+// moves the respondent towards PositiveLabel.
+type Axis struct {
+	ID            uint   `json:"-" gorm:"primaryKey"`
+	PolcompassID  uint   `json:"-" gorm:"uniqueIndex:idx_polcompass_axis"`
+	Position      int    `json:"-"`
+	Name          string `json:"name" gorm:"uniqueIndex:idx_polcompass_axis,length:191" validate:"required"`
+	NegativeLabel string `json:"negative_label"`
+	PositiveLabel string `json:"positive_label"`
+	QuestionQty   int    `json:"question_qty"`
+}
+
+// axes returns the axes of the request, falling back on the two field names
+// for clients that predate Axis.
+func (req PolCompassReq) axes() []Axis {
+	if len(req.Axes) == 0 {
+		return []Axis{{Name: req.Field1Name}, {Name: req.Field2Name}}
+	}
+	axes := make([]Axis, len(req.Axes))
+	copy(axes, req.Axes)
+	return axes
+}
+
+// countQuestions sets the QuestionQty of every axis and rejects questions
+// with an effect on an unknown axis.
+func countQuestions(axes []Axis, questions []Question) error {
+	if len(axes) == 0 {
+		return badRequest(CodeInvalidAxes, "A polcompass needs at least one axis")
+	}
+
+	// the axes of a PATCH come partly from the stored polcompass, the
+	// validate tags could not check them
+	var invalid []FieldError
+	byName := make(map[string]bool, len(axes))
+	for i := range axes {
+		field := fmt.Sprintf("axes[%d].name", i)
+		switch {
+		case axes[i].Name == "":
+			invalid = append(invalid, FieldError{Field: field, Rule: "required", Message: "is required"})
+		case byName[axes[i].Name]:
+			invalid = append(invalid, FieldError{Field: field, Rule: "unique", Message: "is already used earlier in the request"})
+		}
+		byName[axes[i].Name] = true
+	}
+	if len(invalid) > 0 {
+		return invalidFields(invalid)
+	}
+
+	if unknown := unknownAxes(axes, questions); len(unknown) > 0 {
+		return invalidFields(unknown)
+	}
+
+	for _, q := range questions {
+		for _, e := range q.effectList() {
+			if math.IsNaN(e.Weight) || math.IsInf(e.Weight, 0) {
+				return badRequest(CodeValidationFailed, "The weight of the question "+q.Question+" on "+e.Axis+" must be a number")
+			}
+		}
+	}
+
+	counts := questionCounts(questions)
+	for i := range axes {
+		axes[i].QuestionQty = counts[axes[i].Name]
+	}
+
+	return nil
+}
+
+// unknownAxes lists the affects and effects of questions naming an axis
+// missing from axes, with the path of the field in the request.
+func unknownAxes(axes []Axis, questions []Question) []FieldError {
+	byName := make(map[string]bool, len(axes))
+	names := make([]string, 0, len(axes))
+	for _, axis := range axes {
+		byName[axis.Name] = true
+		names = append(names, axis.Name)
+	}
+	message := "must be one of the axes " + strings.Join(names, ", ")
+
+	var fieldErrors []FieldError
+	for i, q := range questions {
+		if len(q.Effects) == 0 {
+			if q.Affects != "" && !byName[q.Affects] {
+				fieldErrors = append(fieldErrors, FieldError{Field: fmt.Sprintf("questions[%d].affects", i), Rule: "axis", Message: message})
+			}
+			continue
+		}
+		for j, e := range q.Effects {
+			if e.Axis != "" && !byName[e.Axis] {
+				fieldErrors = append(fieldErrors, FieldError{Field: fmt.Sprintf("questions[%d].effects[%d].axis", i, j), Rule: "axis", Message: message})
+			}
+		}
+	}
+	return fieldErrors
+}
+
+// questionCounts returns how many questions move each axis, a question
+// with several effects on an axis counts once.
+func questionCounts(questions []Question) map[string]int {
+	counts := make(map[string]int)
+	for _, q := range questions {
+		affected := make(map[string]bool)
+		for _, e := range q.effectList() {
+			if !affected[e.Axis] {
+				affected[e.Axis] = true
+				counts[e.Axis]++
+			}
+		}
+	}
+	return counts
+}
+
+// recountQuestions derives the question counts of the axes from the loaded
+// questions. The stored counts are only written when the polcompass is saved,
+// they go stale when the questions table is edited directly.
+func (p *Polcompass) recountQuestions() {
+	counts := questionCounts(p.Questions)
+	for i := range p.Axes {
+		p.Axes[i].QuestionQty = counts[p.Axes[i].Name]
+	}
+	p.Field1QuestionQty, p.Field2QuestionQty = 0, 0
+	if p.Field1Name != "" {
+		p.Field1QuestionQty = counts[p.Field1Name]
+	}
+	if p.Field2Name != "" {
+		p.Field2QuestionQty = counts[p.Field2Name]
+	}
+}
+
+// setAxes attaches axes to the polcompass and mirrors the first two of them
+// on the legacy Field1/Field2 columns.
+func (p *Polcompass) setAxes(axes []Axis) {
+	p.Field1Name, p.Field1QuestionQty = "", 0
+	p.Field2Name, p.Field2QuestionQty = "", 0
+
+	for i := range axes {
+		axes[i].ID = 0
+		axes[i].PolcompassID = p.ID
+		axes[i].Position = i
+	}
+	if len(axes) > 0 {
+		p.Field1Name, p.Field1QuestionQty = axes[0].Name, axes[0].QuestionQty
+	}
+	if len(axes) > 1 {
+		p.Field2Name, p.Field2QuestionQty = axes[1].Name, axes[1].QuestionQty
+	}
+	p.Axes = axes
+}
+
+// axisList returns the axes of the polcompass, rebuilding them from the
+// Field1/Field2 columns when they were not loaded.
+func (p Polcompass) axisList() []Axis {
+	if len(p.Axes) > 0 {
+		return p.Axes
+	}
+	return []Axis{
+		{PolcompassID: p.ID, Position: 0, Name: p.Field1Name, QuestionQty: p.Field1QuestionQty},
+		{PolcompassID: p.ID, Position: 1, Name: p.Field2Name, QuestionQty: p.Field2QuestionQty},
+	}
+}
+
+// preloadCompass loads the questions, the ordered axes and the reference
+// points of a polcompass.
+func preloadCompass(db *gorm.DB) *gorm.DB {
+	return db.Preload("Questions").Preload("Axes", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).Preload("References", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("References.Answers", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	})
+}
+
+// findCompass loads a polcompass with preloadCompass and derives its question
+// counts from its questions.
+func findCompass(db *gorm.DB, polcompass *Polcompass, conds ...interface{}) error {
+	if err := preloadCompass(db).First(polcompass, conds...).Error; err != nil {
+		return err
+	}
+	polcompass.recountQuestions()
+	polcompass.Scale = polcompass.answerScale()
+	return nil
+}
@@ -0,0 +1,45 @@
+package models
+
+import "math"
+
+// Effect is how strongly agreeing with a question moves the respondent on an axis,
+// a negative Weight moves them towards the negative pole.
+type Effect struct {
+	Axis   string  `json:"axis" validate:"required"`
+	Weight float64 `json:"weight" validate:"required"`
+}
+
+// effectList returns the effects of the question, questions saved with only
+// Affects and Direction move a single axis by one.
+func (q Question) effectList() []Effect {
+	if len(q.Effects) > 0 {
+		return q.Effects
+	}
+	return []Effect{{Axis: q.Affects, Weight: float64(sign(q.Direction))}}
+}
+
+func weightSign(weight float64) int {
+	if weight > 0 {
+		return 1
+	}
+	if weight < 0 {
+		return -1
+	}
+	return 0
+}
+
+// maxScores returns, for each axis, the score reached by answering every
+// question but the skipped ones with the strongest agreement in its
+// direction, amplitude being how far that answer is from neutral.
+func maxScores(questions []Question, amplitude float64, skipped map[uint]bool) map[string]float64 {
+	maxScores := make(map[string]float64)
+	for _, q := range questions {
+		if skipped[q.ID] {
+			continue
+		}
+		for _, e := range q.effectList() {
+			maxScores[e.Axis] += math.Abs(e.Weight) * amplitude
+		}
+	}
+	return maxScores
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PartyMatch is how much a respondent agrees with a reference point placed by
+// its answers, Agreement is a percentage. It is null when the respondent and
+// the party answered no question in common.
+type PartyMatch struct {
+	ReferenceID uint            `json:"reference_id"`
+	Name        string          `json:"name"`
+	Agreement   *float64        `json:"agreement"`
+	Questions   []QuestionMatch `json:"questions"`
+}
+
+// QuestionMatch compares the answers of a respondent and of a party to a
+// question, Agreement goes from 0 when they sit at both ends of the scale to
+// 1 when they are the same.
+type QuestionMatch struct {
+	QuestionID uint    `json:"question_id"`
+	Question   string  `json:"question"`
+	Value      int     `json:"value"`
+	PartyValue int     `json:"party_value"`
+	Important  bool    `json:"important"`
+	Agreement  float64 `json:"agreement"`
+}
+
+type MatchResponse struct {
+	Parties []PartyMatch `json:"parties"`
+}
+
+// Match compares the answers of a respondent with the reference points of the
+// polcompass which were given answers, the best match first and the parties
+// without a question in common last.
+func (p *PolCompassController) Match(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req ScoreReq
+	err := json.NewDecoder(c.Request.Body).Decode(&req)
+	if err != nil {
+		respondError(c, badRequest(CodeInvalidBody, "Bad request for match request answers [{question_id uint, value int, important bool}] "))
+		return
+	}
+
+	polcompass, err := p.Store.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	match, err := ComputeMatch(polcompass, req.Answers)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, match)
+}
+
+// ComputeMatch averages the agreement on every question answered by both the
+// respondent and a party, the questions marked important count twice.
+// Skipped questions are left out on both sides.
+func ComputeMatch(polcompass Polcompass, answers []AnswerReq) (MatchResponse, error) {
+	if err := checkAnswers(polcompass, answers); err != nil {
+		return MatchResponse{}, err
+	}
+
+	given := answerMap(answers)
+	_, amplitude := polcompass.answerScale().neutral()
+
+	match := MatchResponse{Parties: []PartyMatch{}}
+	for _, reference := range polcompass.References {
+		if len(reference.Answers) == 0 {
+			continue
+		}
+		partyAnswers := answerMap(reference.answerReqs())
+
+		party := PartyMatch{ReferenceID: reference.ID, Name: reference.Name, Questions: []QuestionMatch{}}
+		var total, weights float64
+		for _, q := range polcompass.Questions {
+			answer, isPresent := given[q.ID]
+			if !isPresent || answer.Skipped {
+				continue
+			}
+			partyAnswer, isPresent := partyAnswers[q.ID]
+			if !isPresent || partyAnswer.Skipped {
+				continue
+			}
+
+			questionMatch := QuestionMatch{
+				QuestionID: q.ID,
+				Question:   q.Question,
+				Value:      answer.Value,
+				PartyValue: partyAnswer.Value,
+				Important:  answer.Important,
+				Agreement:  agreement(answer.Value, partyAnswer.Value, amplitude),
+			}
+			party.Questions = append(party.Questions, questionMatch)
+
+			weight := 1.0
+			if answer.Important {
+				weight = 2
+			}
+			total += weight * questionMatch.Agreement
+			weights += weight
+		}
+
+		if weights > 0 {
+			percentage := 100 * total / weights
+			party.Agreement = &percentage
+		}
+		match.Parties = append(match.Parties, party)
+	}
+
+	sort.SliceStable(match.Parties, func(i, j int) bool {
+		a, b := match.Parties[i].Agreement, match.Parties[j].Agreement
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		return *a > *b
+	})
+
+	return match, nil
+}
+
+// agreement is 1 minus the distance between two answers over the width of
+// the scale, answers from an older scale may fall outside of it.
+func agreement(value int, partyValue int, amplitude float64) float64 {
+	if amplitude == 0 {
+		return 1
+	}
+	distance := math.Abs(float64(value-partyValue)) / (2 * amplitude)
+	return math.Max(0, 1-distance)
+}
+
+func answerMap(answers []AnswerReq) map[uint]AnswerReq {
+	byQuestion := make(map[uint]AnswerReq, len(answers))
+	for _, a := range answers {
+		byQuestion[a.QuestionID] = a
+	}
+	return byQuestion
+}
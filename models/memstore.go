@@ -0,0 +1,335 @@
+package models
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryStore is a CompassStore keeping everything in memory, it is meant for
+// tests and for running the API without a database.
+type MemoryStore struct {
+	mu           sync.RWMutex
+	compasses    map[uint]Polcompass
+	responses    map[string]Response
+	lastID       uint
+	lastAxisID   uint
+	lastQID      uint
+	lastRespID   uint
+	lastAnswerID uint
+	lastRefID    uint
+	lastRefAnsID uint
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		compasses: make(map[uint]Polcompass),
+		responses: make(map[string]Response),
+	}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id uint) (Polcompass, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	polcompass, isPresent := s.compasses[id]
+	if !isPresent {
+		return Polcompass{}, errCompassNotFound
+	}
+	if polcompass.DeletedAt.Valid {
+		return Polcompass{}, errCompassDeleted
+	}
+	return cloneCompass(polcompass), nil
+}
+
+func (s *MemoryStore) First(ctx context.Context) (Polcompass, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, id := range s.sortedIDs() {
+		if polcompass := s.compasses[id]; !polcompass.DeletedAt.Valid {
+			return cloneCompass(polcompass), nil
+		}
+	}
+	return Polcompass{}, errCompassNotFound
+}
+
+func (s *MemoryStore) ListSummaries(ctx context.Context, page int, perPage int) ([]Summary, int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	all := []Summary{}
+	for _, id := range s.sortedIDs() {
+		polcompass := s.compasses[id]
+		if polcompass.DeletedAt.Valid || polcompass.Name == "" || polcompass.Description == "" {
+			continue
+		}
+		all = append(all, Summary{ID: polcompass.ID, Name: polcompass.Name, Description: polcompass.Description})
+	}
+
+	start := (page - 1) * perPage
+	if start > len(all) {
+		start = len(all)
+	}
+	end := start + perPage
+	if end > len(all) {
+		end = len(all)
+	}
+
+	return all[start:end], int64(len(all)), nil
+}
+
+func (s *MemoryStore) Create(ctx context.Context, polcompass *Polcompass) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	now := time.Now()
+	polcompass.ID = s.lastID
+	polcompass.CreatedAt = now
+	polcompass.UpdatedAt = now
+	polcompass.DeletedAt = gorm.DeletedAt{}
+	polcompass.References = nil
+
+	s.store(polcompass, nil)
+	return nil
+}
+
+func (s *MemoryStore) Update(ctx context.Context, polcompass *Polcompass, renamed map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, isPresent := s.compasses[polcompass.ID]
+	if !isPresent || existing.DeletedAt.Valid {
+		return errCompassNotFound
+	}
+
+	polcompass.CreatedAt = existing.CreatedAt
+	polcompass.UpdatedAt = time.Now()
+	polcompass.DeletedAt = gorm.DeletedAt{}
+	polcompass.References = existing.References
+
+	s.store(polcompass, renamed)
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	polcompass, isPresent := s.compasses[id]
+	if !isPresent {
+		return errCompassNotFound
+	}
+	if polcompass.DeletedAt.Valid {
+		return errCompassDeleted
+	}
+	polcompass.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	s.compasses[id] = polcompass
+	return nil
+}
+
+func (s *MemoryStore) Restore(ctx context.Context, id uint) (Polcompass, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	polcompass, isPresent := s.compasses[id]
+	if !isPresent || !polcompass.DeletedAt.Valid {
+		return Polcompass{}, errNoDeletedCompass
+	}
+	polcompass.DeletedAt = gorm.DeletedAt{}
+	s.compasses[id] = polcompass
+	return cloneCompass(polcompass), nil
+}
+
+func (s *MemoryStore) CreateResponse(ctx context.Context, response *Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, isPresent := s.responses[response.PublicID]; isPresent {
+		return internalError("Error while saving the response")
+	}
+
+	s.lastRespID++
+	response.ID = s.lastRespID
+	response.CreatedAt = time.Now()
+	for i := range response.Answers {
+		s.lastAnswerID++
+		response.Answers[i].ID = s.lastAnswerID
+		response.Answers[i].ResponseID = response.ID
+	}
+
+	stored := *response
+	stored.Answers = append([]Answer(nil), response.Answers...)
+	s.responses[response.PublicID] = stored
+	return nil
+}
+
+func (s *MemoryStore) GetResponse(ctx context.Context, publicID string) (Response, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	response, isPresent := s.responses[publicID]
+	if !isPresent {
+		return Response{}, errResponseNotFound
+	}
+	response.Answers = append([]Answer(nil), response.Answers...)
+	return response, nil
+}
+
+func (s *MemoryStore) CreateReference(ctx context.Context, reference *ReferencePoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	polcompass, isPresent := s.compasses[reference.PolcompassID]
+	if !isPresent || polcompass.DeletedAt.Valid {
+		return errCompassNotFound
+	}
+
+	s.lastRefID++
+	now := time.Now()
+	reference.ID = s.lastRefID
+	reference.CreatedAt = now
+	reference.UpdatedAt = now
+	s.numberAnswers(reference)
+
+	polcompass.References = append(polcompass.References, *reference)
+	s.compasses[polcompass.ID] = cloneCompass(polcompass)
+	return nil
+}
+
+func (s *MemoryStore) UpdateReference(ctx context.Context, reference *ReferencePoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	polcompass := s.compasses[reference.PolcompassID]
+	for i, existing := range polcompass.References {
+		if existing.ID == reference.ID {
+			reference.CreatedAt = existing.CreatedAt
+			reference.UpdatedAt = time.Now()
+			s.numberAnswers(reference)
+			polcompass.References[i] = *reference
+			s.compasses[polcompass.ID] = cloneCompass(polcompass)
+			return nil
+		}
+	}
+	return errReferenceNotFound
+}
+
+func (s *MemoryStore) DeleteReference(ctx context.Context, polcompassID uint, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	polcompass := s.compasses[polcompassID]
+	for i, existing := range polcompass.References {
+		if existing.ID == id {
+			polcompass.References = append(polcompass.References[:i:i], polcompass.References[i+1:]...)
+			s.compasses[polcompassID] = cloneCompass(polcompass)
+			return nil
+		}
+	}
+	return errReferenceNotFound
+}
+
+func (s *MemoryStore) numberAnswers(reference *ReferencePoint) {
+	for i := range reference.Answers {
+		s.lastRefAnsID++
+		reference.Answers[i].ID = s.lastRefAnsID
+		reference.Answers[i].ReferencePointID = reference.ID
+	}
+}
+
+// store numbers the axes and questions of polcompass and saves a copy of it,
+// questions sharing a text are merged and the questions already stored keep
+// their ids like in the gorm store. The reference points are replotted,
+// following the axes in renamed.
+func (s *MemoryStore) store(polcompass *Polcompass, renamed map[string]string) {
+	axes := make([]Axis, len(polcompass.Axes))
+	for i, axis := range polcompass.Axes {
+		s.lastAxisID++
+		axis.ID = s.lastAxisID
+		axis.PolcompassID = polcompass.ID
+		axis.Position = i
+		axes[i] = axis
+	}
+
+	existing := make(map[string]uint)
+	for _, q := range s.compasses[polcompass.ID].Questions {
+		existing[q.Question] = q.ID
+	}
+
+	questions := uniqueQuestions(polcompass.Questions)
+	for i, q := range questions {
+		q = prepareQuestion(q, polcompass.ID)
+		if id, isPresent := existing[q.Question]; isPresent {
+			q.ID = id
+		} else {
+			s.lastQID++
+			q.ID = s.lastQID
+		}
+		questions[i] = q
+	}
+
+	polcompass.Axes = axes
+	polcompass.Questions = questions
+	polcompass.References = dropRemovedAnswers(polcompass.References, questions)
+	polcompass.replot(renamed)
+	s.compasses[polcompass.ID] = cloneCompass(*polcompass)
+}
+
+// dropRemovedAnswers removes the answers of the reference points to the
+// questions no longer in questions, like the cascade of the gorm store.
+func dropRemovedAnswers(references []ReferencePoint, questions []Question) []ReferencePoint {
+	kept := make(map[uint]bool, len(questions))
+	for _, q := range questions {
+		kept[q.ID] = true
+	}
+	result := make([]ReferencePoint, len(references))
+	for i, reference := range references {
+		var answers []ReferenceAnswer
+		for _, a := range reference.Answers {
+			if kept[a.QuestionID] {
+				answers = append(answers, a)
+			}
+		}
+		reference.Answers = answers
+		result[i] = reference
+	}
+	return result
+}
+
+func (s *MemoryStore) sortedIDs() []uint {
+	ids := make([]uint, 0, len(s.compasses))
+	for id := range s.compasses {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// cloneCompass copies the slices of a polcompass so callers cannot change
+// what the store holds.
+func cloneCompass(polcompass Polcompass) Polcompass {
+	axes := make([]Axis, len(polcompass.Axes))
+	copy(axes, polcompass.Axes)
+	polcompass.Axes = axes
+	questions := make([]Question, len(polcompass.Questions))
+	for i, q := range polcompass.Questions {
+		q.Effects = append([]Effect(nil), q.Effects...)
+		questions[i] = q
+	}
+	polcompass.Questions = questions
+	polcompass.Scale.Options = append([]ScaleOption(nil), polcompass.Scale.Options...)
+	polcompass.Regions = cloneRegions(polcompass.Regions)
+	references := make([]ReferencePoint, len(polcompass.References))
+	for i, reference := range polcompass.References {
+		reference.Scores = append([]AxisScore(nil), reference.Scores...)
+		reference.Answers = append([]ReferenceAnswer(nil), reference.Answers...)
+		references[i] = reference
+	}
+	polcompass.References = references
+	return polcompass
+}
@@ -2,25 +2,30 @@ package models
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
 type PolCompassController struct {
-	DB *gorm.DB
+	Store CompassStore
+}
+
+func NewPolCompassController(store CompassStore) *PolCompassController {
+	return &PolCompassController{Store: store}
 }
 
 type PolCompassReq struct {
-	Field1Name  string     `json:"field1_name"`
-	Field2Name  string     `json:"field2_name"`
-	Name        string     `json:"name"`
+	Field1Name  string     `json:"field1_name" validate:"required_without=Axes"`
+	Field2Name  string     `json:"field2_name" validate:"required_without=Axes,omitempty,nefield=Field1Name"`
+	Name        string     `json:"name" validate:"required"`
 	Description string     `json:"description"`
-	Questions   []Question `json:"questions"`
+	Axes        []Axis     `json:"axes" validate:"omitempty,dive"`
+	Questions   []Question `json:"questions" validate:"dive"`
+	Scale       *Scale     `json:"scale"`
+	Regions     []Region   `json:"regions" validate:"dive"`
 }
 
 type Polcompass struct {
@@ -31,50 +36,62 @@ type Polcompass struct {
 	Field2QuestionQty int
 	Name              string
 	Description       string
+	Axes              []Axis     `json:"axes"`
 	Questions         []Question `json:"questions"`
+	Scale             Scale      `json:"scale" gorm:"serializer:json"`
+	// Regions name areas of the plane of the first two axes, the scores
+	// falling in one of them get it as their archetype.
+	Regions []Region `json:"regions" gorm:"serializer:json"`
+	// References are the parties and figures plotted on the polcompass, the
+	// stores ignore them when saving a polcompass.
+	References []ReferencePoint `json:"references"`
 }
 type Question struct {
-	ID           uint   `gorm:"primaryKey"` // Or gorm.Model is embedded
-	Question     string `json:"question" gorm:"column:question;uniqueIndex:idx_polcompass_question"`
-	Affects      string `json:"affects"`
-	Direction    int    `json:"direction"`
-	PolcompassID uint   `json:"-" gorm:"uniqueIndex:idx_polcompass_question"`
+	ID uint `gorm:"primaryKey"` // Or gorm.Model is embedded
+	// mysql cannot index a whole text column, the first 191 characters are unique
+	Question  string `json:"question" gorm:"column:question;uniqueIndex:idx_polcompass_question,length:191" validate:"required"`
+	Affects   string `json:"affects" validate:"required_without=Effects"`
+	Direction int    `json:"direction" validate:"required_without=Effects,omitempty,oneof=-1 1"`
+	// Effects lets a question move several axes, Affects and Direction hold the first one
+	Effects      []Effect `json:"effects" gorm:"serializer:json" validate:"omitempty,dive"`
+	PolcompassID uint     `json:"-" gorm:"uniqueIndex:idx_polcompass_question"`
 }
 
 func (p *PolCompassController) GET(c *gin.Context) {
-	reqID, isPresent := c.GetQuery("id")
+	reqID := c.Param("id")
 
-	if !isPresent {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "You need to specify an id",
-		})
-		return
-	}
+	if reqID == "" {
+		var isPresent bool
+		reqID, isPresent = c.GetQuery("id")
 
-	var polcompass Polcompass
+		if !isPresent {
+			respondError(c, badRequest(CodeMissingParameter, "You need to specify an id"))
+			return
+		}
+	}
 
 	polcompassId64, err := strconv.ParseUint(reqID, 10, 64)
 	polcompassId := uint(polcompassId64)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "You need to specify an id",
-		})
+		respondError(c, badRequest(CodeInvalidID, "id must be a positive integer"))
 		return
 	}
 
-	p.DB.Preload("Questions").First(&polcompass, polcompassId)
+	polcompass, err := p.Store.Get(c.Request.Context(), polcompassId)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
 
 	c.JSON(http.StatusOK, polcompass)
 
 }
 
 func (p *PolCompassController) First(c *gin.Context) {
-	var polcompass Polcompass
-	if err := p.DB.Preload("Questions").First(&polcompass).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"message": "PolCompass not found",
-		})
+	polcompass, err := p.Store.First(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, polcompass)
@@ -85,57 +102,39 @@ func (p *PolCompassController) POST(c *gin.Context) {
 	var req PolCompassReq
 	err := json.NewDecoder(c.Request.Body).Decode(&req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "Bad request for polcompass request field1_name string,field2_name string, questions [] ",
-		})
+		respondError(c, badRequest(CodeInvalidBody, "Bad request for polcompass request field1_name string,field2_name string, questions [] "))
 		return
 	}
 
-	field1QuestionQty := 0
-	field2QuestionQty := 0
-
-	//Count questions for the frontend
-
-	for _, q := range req.Questions {
-		fmt.Print(q)
-		if q.Affects == req.Field1Name {
-			field1QuestionQty++
-		} else if q.Affects == req.Field2Name {
-			field2QuestionQty++
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"message": "An unknown field was added in the questions : " + q.Affects + " fields names are : " + req.Field1Name + " and " + req.Field2Name,
-			})
-			return
-		}
-		q.ID = 0
+	if !validateRequest(c, req, unknownAxes(req.axes(), req.Questions)...) {
+		return
 	}
-	newPolCompass := Polcompass{Field1Name: req.Field1Name, Field2Name: req.Field2Name, Field1QuestionQty: field1QuestionQty, Field2QuestionQty: field2QuestionQty, Name: req.Name, Description: req.Description}
-
-	p.DB.Create(&newPolCompass)
-
-	var questionsToSave []Question
 
-	for _, q := range req.Questions {
-		q.PolcompassID = newPolCompass.ID
-		questionsToSave = append(questionsToSave, q)
+	//Count questions for the frontend
+	axes := req.axes()
+	if err := countQuestions(axes, req.Questions); err != nil {
+		respondError(c, err)
+		return
 	}
 
-	result := p.DB.Clauses(clause.OnConflict{
-		Columns: []clause.Column{{Name: "question"}, {Name: "polcompass_id"}},
-		DoUpdates: clause.Assignments(map[string]interface{}{"direction": clause.Expr{SQL: "excluded.direction"}, "affects": clause.Expr{SQL: "excluded.affects"},
-			"polcompass_id": clause.Expr{SQL: "excluded.polcompass_id"}}),
-	}).Create(&questionsToSave)
+	newPolCompass := Polcompass{Name: req.Name, Description: req.Description, Questions: req.Questions, Scale: req.scale(), Regions: req.Regions}
+	newPolCompass.setAxes(axes)
 
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"message": "Error while saving questions to the database",
-		})
+	if err := p.Store.Create(c.Request.Context(), &newPolCompass); err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Added sucessfully to the database",
-	})
+	c.JSON(http.StatusOK, newPolCompass)
+
+}
 
+// parseID reads the :id path parameter of the request.
+func parseID(c *gin.Context) (uint, bool) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, badRequest(CodeInvalidID, "id must be a positive integer"))
+		return 0, false
+	}
+	return uint(id64), true
 }
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// CountMismatch is a stored question count that disagrees with the questions
+// of its polcompass.
+type CountMismatch struct {
+	PolcompassID uint
+	Axis         string
+	// Column is the stale column: polcompasses.field1_question_qty,
+	// polcompasses.field2_question_qty or axes.question_qty.
+	Column string
+	Stored int
+	Actual int
+}
+
+// AuditQuestionCounts compares the stored question counts of every
+// polcompass, deleted ones included, with its questions. When repair is
+// set the stale counts are rewritten, without touching updated_at.
+func (s *GormStore) AuditQuestionCounts(ctx context.Context, repair bool) ([]CountMismatch, error) {
+	db := s.DB.WithContext(ctx)
+	var mismatches []CountMismatch
+	var polcompasses []Polcompass
+	err := preloadCompass(db.Unscoped()).FindInBatches(&polcompasses, 100, func(tx *gorm.DB, batch int) error {
+		for _, stored := range polcompasses {
+			derived := cloneCompass(stored)
+			derived.recountQuestions()
+
+			found := countMismatches(stored, derived)
+			if repair && len(found) > 0 {
+				if err := saveCounts(db, derived); err != nil {
+					return err
+				}
+			}
+			mismatches = append(mismatches, found...)
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return mismatches, databaseError(ctx, "Error while auditing the question counts", err)
+	}
+	return mismatches, nil
+}
+
+func countMismatches(stored Polcompass, derived Polcompass) []CountMismatch {
+	var mismatches []CountMismatch
+	compare := func(axis string, column string, storedQty int, actualQty int) {
+		if storedQty != actualQty {
+			mismatches = append(mismatches, CountMismatch{PolcompassID: stored.ID, Axis: axis, Column: column, Stored: storedQty, Actual: actualQty})
+		}
+	}
+
+	compare(stored.Field1Name, "polcompasses.field1_question_qty", stored.Field1QuestionQty, derived.Field1QuestionQty)
+	compare(stored.Field2Name, "polcompasses.field2_question_qty", stored.Field2QuestionQty, derived.Field2QuestionQty)
+	for i, axis := range stored.Axes {
+		compare(axis.Name, "axes.question_qty", axis.QuestionQty, derived.Axes[i].QuestionQty)
+	}
+	return mismatches
+}
+
+func saveCounts(db *gorm.DB, polcompass Polcompass) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&Polcompass{}).Where("id = ?", polcompass.ID).UpdateColumns(map[string]interface{}{
+			"field1_question_qty": polcompass.Field1QuestionQty,
+			"field2_question_qty": polcompass.Field2QuestionQty,
+		}).Error
+		if err != nil {
+			return err
+		}
+		for _, axis := range polcompass.Axes {
+			if err := tx.Model(&Axis{}).Where("id = ?", axis.ID).UpdateColumn("question_qty", axis.QuestionQty).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,317 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReferencePoint is a party or a public figure plotted on a polcompass, placed
+// either by its scores or by the answers it would give.
+type ReferencePoint struct {
+	ID           uint        `json:"id" gorm:"primaryKey"`
+	PolcompassID uint        `json:"-" gorm:"index"`
+	Name         string      `json:"name"`
+	Description  string      `json:"description"`
+	Source       string      `json:"source"`
+	Field1       float64     `json:"field1"`
+	Field2       float64     `json:"field2"`
+	Scores       []AxisScore `json:"scores" gorm:"serializer:json"`
+	// Answers are kept when the scores were computed from them, they are what
+	// respondents are matched against.
+	Answers   []ReferenceAnswer `json:"answers,omitempty" gorm:"constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// ReferenceAnswer is the answer of a reference point to a question of its
+// polcompass, it goes away with the question.
+type ReferenceAnswer struct {
+	ID               uint      `json:"-" gorm:"primaryKey"`
+	ReferencePointID uint      `json:"-" gorm:"index"`
+	QuestionID       uint      `json:"question_id" gorm:"index"`
+	Question         *Question `json:"-" gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE"`
+	Value            int       `json:"value"`
+	Skipped          bool      `json:"skipped"`
+}
+
+// answerReqs returns the answers of the reference point as they are scored.
+func (r ReferencePoint) answerReqs() []AnswerReq {
+	answers := make([]AnswerReq, len(r.Answers))
+	for i, a := range r.Answers {
+		answers[i] = AnswerReq{QuestionID: a.QuestionID, Value: a.Value, Skipped: a.Skipped}
+	}
+	return answers
+}
+
+// ReferencePointReq places a reference point by its Scores, one per axis
+// between -1 and 1, or by the Answers it would give to the questions.
+type ReferencePointReq struct {
+	Name        string      `json:"name" validate:"required"`
+	Description string      `json:"description"`
+	Source      string      `json:"source"`
+	Scores      []AxisScore `json:"scores" validate:"required_without=Answers,excluded_with=Answers"`
+	Answers     []AnswerReq `json:"answers"`
+}
+
+func (p *PolCompassController) ListReferences(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	polcompass, err := p.Store.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, polcompass.References)
+}
+
+func (p *PolCompassController) GetReference(c *gin.Context) {
+	polcompass, referenceID, ok := p.referenceCompass(c)
+	if !ok {
+		return
+	}
+
+	for _, reference := range polcompass.References {
+		if reference.ID == referenceID {
+			c.JSON(http.StatusOK, reference)
+			return
+		}
+	}
+	respondError(c, errReferenceNotFound)
+}
+
+func (p *PolCompassController) CreateReference(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	req, ok := bindReference(c)
+	if !ok {
+		return
+	}
+
+	polcompass, err := p.Store.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	reference := ReferencePoint{PolcompassID: polcompass.ID}
+	if err := reference.place(polcompass, req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := p.Store.CreateReference(c.Request.Context(), &reference); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reference)
+}
+
+func (p *PolCompassController) UpdateReference(c *gin.Context) {
+	polcompass, referenceID, ok := p.referenceCompass(c)
+	if !ok {
+		return
+	}
+
+	req, ok := bindReference(c)
+	if !ok {
+		return
+	}
+
+	reference := ReferencePoint{ID: referenceID, PolcompassID: polcompass.ID}
+	if err := reference.place(polcompass, req); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := p.Store.UpdateReference(c.Request.Context(), &reference); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reference)
+}
+
+func (p *PolCompassController) DeleteReference(c *gin.Context) {
+	polcompass, referenceID, ok := p.referenceCompass(c)
+	if !ok {
+		return
+	}
+
+	if err := p.Store.DeleteReference(c.Request.Context(), polcompass.ID, referenceID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Deleted successfully",
+	})
+}
+
+// referenceCompass reads the :id and :referenceId path parameters and loads
+// the polcompass, so deleted polcompasses answer 410 like the other routes.
+func (p *PolCompassController) referenceCompass(c *gin.Context) (Polcompass, uint, bool) {
+	id, ok := parseID(c)
+	if !ok {
+		return Polcompass{}, 0, false
+	}
+
+	referenceID, err := strconv.ParseUint(c.Param("referenceId"), 10, 64)
+	if err != nil {
+		respondError(c, badRequest(CodeInvalidID, "referenceId must be a positive integer"))
+		return Polcompass{}, 0, false
+	}
+
+	polcompass, err := p.Store.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return Polcompass{}, 0, false
+	}
+	return polcompass, uint(referenceID), true
+}
+
+func bindReference(c *gin.Context) (ReferencePointReq, bool) {
+	var req ReferencePointReq
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		respondError(c, badRequest(CodeInvalidBody, "Bad request for reference point name string, description string, source string, scores [{name string, score float}] or answers [{question_id uint, value int}] "))
+		return req, false
+	}
+	return req, validateRequest(c, req)
+}
+
+// place sets the description and the position of the reference point from
+// req, scoring its answers when it has no scores.
+func (r *ReferencePoint) place(polcompass Polcompass, req ReferencePointReq) error {
+	r.Name, r.Description, r.Source = req.Name, req.Description, req.Source
+	r.Answers = nil
+
+	var scores []AxisScore
+	if len(req.Answers) > 0 {
+		score, err := ComputeScore(polcompass, req.Answers)
+		if err != nil {
+			return err
+		}
+		scores = score.Axes
+		// only respondents mark questions important when matching parties
+		r.Answers = make([]ReferenceAnswer, len(req.Answers))
+		for i, a := range req.Answers {
+			r.Answers[i] = ReferenceAnswer{QuestionID: a.QuestionID, Value: a.Value, Skipped: a.Skipped}
+			if a.Skipped {
+				r.Answers[i].Value = 0
+			}
+		}
+	} else {
+		var err error
+		if scores, err = axisScores(polcompass, req.Scores); err != nil {
+			return err
+		}
+	}
+
+	r.setPosition(scores)
+	return nil
+}
+
+// setPosition places the reference point at scores, Field1 and Field2 repeat
+// the first two of them.
+func (r *ReferencePoint) setPosition(scores []AxisScore) {
+	r.Scores = scores
+	r.Field1, r.Field2 = 0, 0
+	if len(scores) > 0 {
+		r.Field1 = scores[0].Score
+	}
+	if len(scores) > 1 {
+		r.Field2 = scores[1].Score
+	}
+}
+
+// replot moves the reference points once the questions, the axes or the
+// scale of the polcompass changed, and returns them. The points placed by
+// their answers are scored again, the answers no longer fitting the scale
+// count as unanswered. The points placed by their scores follow the axes
+// renamed from the keys to the values of renamed and lose the removed axes.
+func (p *Polcompass) replot(renamed map[string]string) []ReferencePoint {
+	scale := p.answerScale()
+	var replotted []ReferencePoint
+	for i := range p.References {
+		reference := &p.References[i]
+		if len(reference.Answers) == 0 {
+			reference.setPosition(p.rekeyScores(reference.Scores, renamed))
+			replotted = append(replotted, *reference)
+			continue
+		}
+
+		var answers []AnswerReq
+		for _, a := range reference.answerReqs() {
+			if (a.Skipped && scale.AllowSkip) || (!a.Skipped && scale.has(a.Value)) {
+				answers = append(answers, a)
+			}
+		}
+		score, err := ComputeScore(*p, answers)
+		if err != nil {
+			continue
+		}
+		reference.setPosition(score.Axes)
+		replotted = append(replotted, *reference)
+	}
+	return replotted
+}
+
+// rekeyScores renames scores like the axes in renamed and orders them like the
+// axes of the polcompass, the axes without a score sit at 0 and the scores on
+// removed axes are dropped.
+func (p Polcompass) rekeyScores(scores []AxisScore, renamed map[string]string) []AxisScore {
+	byName := make(map[string]float64, len(scores))
+	for _, score := range scores {
+		name := score.Name
+		if newName, isPresent := renamed[name]; isPresent {
+			name = newName
+		}
+		byName[name] = score.Score
+	}
+
+	axes := p.axisList()
+	ordered := make([]AxisScore, 0, len(axes))
+	for _, axis := range axes {
+		ordered = append(ordered, AxisScore{Name: axis.Name, Score: byName[axis.Name]})
+	}
+	return ordered
+}
+
+// axisScores orders scores like the axes of the polcompass, the axes
+// without a score sit at 0.
+func axisScores(polcompass Polcompass, scores []AxisScore) ([]AxisScore, error) {
+	byName := make(map[string]float64, len(scores))
+	for _, score := range scores {
+		if _, isPresent := byName[score.Name]; isPresent {
+			return nil, badRequest(CodeInvalidAxes, "The axis "+score.Name+" has more than one score")
+		}
+		if math.IsNaN(score.Score) || score.Score < -1 || score.Score > 1 {
+			return nil, badRequest(CodeValidationFailed, fmt.Sprintf("The score on %s must be between -1 and 1", score.Name))
+		}
+		byName[score.Name] = score.Score
+	}
+
+	axes := polcompass.axisList()
+	ordered := make([]AxisScore, 0, len(axes))
+	for _, axis := range axes {
+		ordered = append(ordered, AxisScore{Name: axis.Name, Score: byName[axis.Name]})
+		delete(byName, axis.Name)
+	}
+	for name := range byName {
+		return nil, badRequest(CodeUnknownAxis, "The polcompass has no axis named "+name)
+	}
+	return ordered, nil
+}
@@ -0,0 +1,92 @@
+package models
+
+// Region is a named area of the plane of the first two axes, Field1 being x
+// and Field2 being y, both between -1 and 1. A region is one of the four
+// quadrants, a rectangle or a polygon.
+type Region struct {
+	Title       string `json:"title" validate:"required"`
+	Description string `json:"description"`
+	// Quadrant is top_right, top_left, bottom_left or bottom_right, top
+	// being a positive y and right a positive x.
+	Quadrant  string     `json:"quadrant,omitempty" validate:"omitempty,oneof=top_right top_left bottom_left bottom_right"`
+	Rectangle *Rectangle `json:"rectangle,omitempty"`
+	// Polygon lists the vertices in order, the last one joins the first.
+	Polygon []Point `json:"polygon,omitempty" validate:"omitempty,min=3"`
+}
+
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type Rectangle struct {
+	MinX float64 `json:"min_x"`
+	MinY float64 `json:"min_y"`
+	MaxX float64 `json:"max_x" validate:"gtfield=MinX"`
+	MaxY float64 `json:"max_y" validate:"gtfield=MinY"`
+}
+
+// contains tells whether the point is in the region, borders included for
+// quadrants and rectangles.
+func (r Region) contains(p Point) bool {
+	switch {
+	case r.Quadrant != "":
+		switch r.Quadrant {
+		case "top_right":
+			return p.X >= 0 && p.Y >= 0
+		case "top_left":
+			return p.X <= 0 && p.Y >= 0
+		case "bottom_left":
+			return p.X <= 0 && p.Y <= 0
+		case "bottom_right":
+			return p.X >= 0 && p.Y <= 0
+		}
+	case r.Rectangle != nil:
+		return p.X >= r.Rectangle.MinX && p.X <= r.Rectangle.MaxX && p.Y >= r.Rectangle.MinY && p.Y <= r.Rectangle.MaxY
+	case len(r.Polygon) >= 3:
+		return polygonContains(r.Polygon, p)
+	}
+	return false
+}
+
+// polygonContains casts a ray from p towards positive x and counts the
+// edges it crosses, p is inside when it crosses an odd number of them.
+func polygonContains(polygon []Point, p Point) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// archetype returns the first region of the polcompass containing the
+// point, authors list the narrow regions before the broad ones.
+func (p Polcompass) archetype(point Point) *Region {
+	for _, region := range p.Regions {
+		if region.contains(point) {
+			archetype := region
+			return &archetype
+		}
+	}
+	return nil
+}
+
+// cloneRegions copies regions along with their shapes.
+func cloneRegions(regions []Region) []Region {
+	if regions == nil {
+		return nil
+	}
+	cloned := make([]Region, len(regions))
+	for i, region := range regions {
+		if region.Rectangle != nil {
+			rectangle := *region.Rectangle
+			region.Rectangle = &rectangle
+		}
+		region.Polygon = append([]Point(nil), region.Polygon...)
+		cloned[i] = region
+	}
+	return cloned
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Response is a completed run of a polcompass, it is looked up by its PublicID
+// so results can be shared without exposing sequential ids.
+type Response struct {
+	ID           uint        `json:"-" gorm:"primaryKey"`
+	PublicID     string      `json:"id" gorm:"uniqueIndex;size:32"`
+	CreatedAt    time.Time   `json:"created_at"`
+	PolcompassID uint        `json:"polcompass_id" gorm:"index"`
+	Polcompass   Polcompass  `json:"-"`
+	Field1       float64     `json:"field1"`
+	Field2       float64     `json:"field2"`
+	Scores       []AxisScore `json:"scores" gorm:"serializer:json"`
+	Answers      []Answer    `json:"answers"`
+}
+
+type Answer struct {
+	ID         uint      `json:"-" gorm:"primaryKey"`
+	ResponseID uint      `json:"-" gorm:"index"`
+	QuestionID *uint     `json:"question_id" gorm:"index"`
+	Question   *Question `json:"-" gorm:"constraint:OnDelete:SET NULL"`
+	Value      int       `json:"value"`
+	Skipped    bool      `json:"skipped"`
+}
+
+// Submit scores the answers of a respondent and stores them as a Response.
+func (p *PolCompassController) Submit(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req ScoreReq
+	err := json.NewDecoder(c.Request.Body).Decode(&req)
+	if err != nil {
+		respondError(c, badRequest(CodeInvalidBody, "Bad request for score request answers [{question_id uint, value int}] "))
+		return
+	}
+
+	polcompass, err := p.Store.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	score, err := ComputeScore(polcompass, req.Answers)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	publicID, err := newPublicID()
+	if err != nil {
+		respondError(c, internalError("Error while saving the response"))
+		return
+	}
+
+	response := Response{PublicID: publicID, PolcompassID: polcompass.ID, Field1: score.Field1, Field2: score.Field2, Scores: score.Axes}
+	for _, a := range req.Answers {
+		questionID := a.QuestionID
+		answer := Answer{QuestionID: &questionID, Value: a.Value, Skipped: a.Skipped}
+		if a.Skipped {
+			answer.Value = 0
+		}
+		response.Answers = append(response.Answers, answer)
+	}
+
+	if err := p.Store.CreateResponse(c.Request.Context(), &response); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetResponse returns a stored Response from its public id.
+func (p *PolCompassController) GetResponse(c *gin.Context) {
+	response, err := p.Store.GetResponse(c.Request.Context(), c.Param("publicId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func newPublicID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ScaleOption is one answer a respondent can pick.
+type ScaleOption struct {
+	Label string `json:"label" validate:"required"`
+	Value int    `json:"value"`
+}
+
+// Scale lists the answers of the questions of a polcompass. The middle of
+// the values is neutral, higher values agree with a question and lower
+// values disagree, so 1 to 5 and -2 to 2 score the same.
+type Scale struct {
+	Options []ScaleOption `json:"options" validate:"min=2,max=11,dive"`
+	// AllowSkip offers a "don't know" answer, skipped questions count as
+	// neutral and are left out of the normalization of the scores.
+	AllowSkip bool   `json:"allow_skip"`
+	SkipLabel string `json:"skip_label,omitempty"`
+}
+
+// DefaultScale is the five point agree/disagree scale, used by the
+// polcompasses saved without a scale.
+func DefaultScale() Scale {
+	return Scale{Options: []ScaleOption{
+		{Label: "Strongly disagree", Value: -MaxLikertValue},
+		{Label: "Disagree", Value: -1},
+		{Label: "Neutral", Value: 0},
+		{Label: "Agree", Value: 1},
+		{Label: "Strongly agree", Value: MaxLikertValue},
+	}}
+}
+
+// neutral returns the neutral value of the scale and how far its extremes
+// are from it.
+func (s Scale) neutral() (center float64, amplitude float64) {
+	if len(s.Options) == 0 {
+		return 0, 0
+	}
+	lowest, highest := s.Options[0].Value, s.Options[0].Value
+	for _, option := range s.Options {
+		lowest = min(lowest, option.Value)
+		highest = max(highest, option.Value)
+	}
+	return float64(lowest+highest) / 2, float64(highest-lowest) / 2
+}
+
+func (s Scale) has(value int) bool {
+	for _, option := range s.Options {
+		if option.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// values lists the values of the scale for error messages.
+func (s Scale) values() string {
+	values := make([]string, len(s.Options))
+	for i, option := range s.Options {
+		values[i] = strconv.Itoa(option.Value)
+	}
+	return strings.Join(values, ", ")
+}
+
+// answerScale returns the scale of the polcompass, or the default scale for
+// the polcompasses saved before scales existed.
+func (p Polcompass) answerScale() Scale {
+	if len(p.Scale.Options) == 0 {
+		return DefaultScale()
+	}
+	return p.Scale
+}
+
+// scale returns the scale of the request, the default one when it sets none.
+func (req PolCompassReq) scale() Scale {
+	if req.Scale == nil {
+		return DefaultScale()
+	}
+	return *req.Scale
+}
@@ -0,0 +1,252 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxLikertValue is the strongest answer on the default scale, its answers
+// go from -MaxLikertValue (strongly disagree) to MaxLikertValue (strongly agree).
+const MaxLikertValue = 2
+
+type AnswerReq struct {
+	QuestionID uint `json:"question_id"`
+	Value      int  `json:"value"`
+	// Skipped answers "don't know" on the scales allowing it, Value is ignored.
+	Skipped bool `json:"skipped"`
+	// Important counts the question twice when matching parties, scoring
+	// ignores it.
+	Important bool `json:"important,omitempty"`
+}
+
+type ScoreReq struct {
+	Answers []AnswerReq `json:"answers"`
+}
+
+// ScoreResponse holds the position of a respondent, each score is normalized between -1 and 1.
+// Field1 and Field2 repeat the scores of the first two axes.
+type ScoreResponse struct {
+	Field1Name string      `json:"field1_name"`
+	Field2Name string      `json:"field2_name"`
+	Field1     float64     `json:"field1"`
+	Field2     float64     `json:"field2"`
+	Axes       []AxisScore `json:"axes"`
+	// Archetype is the first region of the polcompass containing Field1 and
+	// Field2, null when none does.
+	Archetype *Region `json:"archetype"`
+	// Contributions explain the score of every axis, in the order of Axes.
+	Contributions []AxisContributions `json:"contributions"`
+	// Skipped lists the questions answered "don't know", they are left out of
+	// the strongest scores the axes are divided by.
+	Skipped []SkippedQuestion `json:"skipped"`
+	// Unanswered lists the questions missing from the answers, they count as
+	// neutral answers and still weigh in the strongest scores.
+	Unanswered []SkippedQuestion `json:"unanswered"`
+}
+
+// AxisContributions lists the answered questions moving an axis, the
+// strongest first. Their contributions add up to the score of the axis.
+type AxisContributions struct {
+	Axis      string                 `json:"axis"`
+	Questions []QuestionContribution `json:"questions"`
+}
+
+// QuestionContribution is how far an answer moved an axis, its sign follows
+// the direction of the question and its size the strength of the answer.
+type QuestionContribution struct {
+	QuestionID   uint    `json:"question_id"`
+	Question     string  `json:"question"`
+	Value        int     `json:"value"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+}
+
+type SkippedQuestion struct {
+	QuestionID uint   `json:"question_id"`
+	Question   string `json:"question"`
+}
+
+type AxisScore struct {
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+func (p *PolCompassController) Score(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req ScoreReq
+	err := json.NewDecoder(c.Request.Body).Decode(&req)
+	if err != nil {
+		respondError(c, badRequest(CodeInvalidBody, "Bad request for score request answers [{question_id uint, value int}] "))
+		return
+	}
+
+	polcompass, err := p.Store.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	score, err := ComputeScore(polcompass, req.Answers)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, score)
+}
+
+// ComputeScore sums how far every answer is from the neutral value of the
+// scale, weighted by the effects of its question, and divides each axis by
+// the strongest score its questions allow. Skipped questions move no axis
+// and are left out of that strongest score.
+func ComputeScore(polcompass Polcompass, answers []AnswerReq) (ScoreResponse, error) {
+	questions := make(map[uint]Question, len(polcompass.Questions))
+	for _, q := range polcompass.Questions {
+		questions[q.ID] = q
+	}
+
+	axes := polcompass.axisList()
+	sums := make(map[string]float64, len(axes))
+	for _, axis := range axes {
+		sums[axis.Name] = 0
+	}
+
+	if err := checkAnswers(polcompass, answers); err != nil {
+		return ScoreResponse{}, err
+	}
+
+	center, amplitude := polcompass.answerScale().neutral()
+
+	answered := make(map[uint]bool, len(answers))
+	skipped := make(map[uint]bool)
+	contributions := make(map[string][]QuestionContribution, len(axes))
+
+	for _, a := range answers {
+		if a.Skipped {
+			skipped[a.QuestionID] = true
+			continue
+		}
+		answered[a.QuestionID] = true
+
+		q := questions[a.QuestionID]
+		for _, e := range q.effectList() {
+			if _, isPresent := sums[e.Axis]; !isPresent {
+				return ScoreResponse{}, internalError("An unknown field was found in the questions : " + e.Axis)
+			}
+			contribution := e.Weight * (float64(a.Value) - center)
+			sums[e.Axis] += contribution
+			contributions[e.Axis] = append(contributions[e.Axis], QuestionContribution{
+				QuestionID:   q.ID,
+				Question:     q.Question,
+				Value:        a.Value,
+				Weight:       e.Weight,
+				Contribution: contribution,
+			})
+		}
+	}
+
+	maxScores := maxScores(polcompass.Questions, amplitude, skipped)
+
+	score := ScoreResponse{Contributions: []AxisContributions{}, Skipped: []SkippedQuestion{}, Unanswered: []SkippedQuestion{}}
+	for i, axis := range axes {
+		axisScore := AxisScore{Name: axis.Name, Score: normalize(sums[axis.Name], maxScores[axis.Name])}
+		score.Axes = append(score.Axes, axisScore)
+		score.Contributions = append(score.Contributions, AxisContributions{
+			Axis:      axis.Name,
+			Questions: sortContributions(contributions[axis.Name], maxScores[axis.Name]),
+		})
+
+		switch i {
+		case 0:
+			score.Field1Name, score.Field1 = axisScore.Name, axisScore.Score
+		case 1:
+			score.Field2Name, score.Field2 = axisScore.Name, axisScore.Score
+		}
+	}
+
+	score.Archetype = polcompass.archetype(Point{X: score.Field1, Y: score.Field2})
+
+	for _, q := range polcompass.Questions {
+		switch {
+		case skipped[q.ID]:
+			score.Skipped = append(score.Skipped, SkippedQuestion{QuestionID: q.ID, Question: q.Question})
+		case !answered[q.ID]:
+			score.Unanswered = append(score.Unanswered, SkippedQuestion{QuestionID: q.ID, Question: q.Question})
+		}
+	}
+
+	return score, nil
+}
+
+// checkAnswers returns an error when an answer is to a question of another
+// polcompass, repeats a question or is not on the scale of the polcompass.
+func checkAnswers(polcompass Polcompass, answers []AnswerReq) error {
+	questions := make(map[uint]bool, len(polcompass.Questions))
+	for _, q := range polcompass.Questions {
+		questions[q.ID] = true
+	}
+
+	scale := polcompass.answerScale()
+	answered := make(map[uint]bool, len(answers))
+	for _, a := range answers {
+		if !questions[a.QuestionID] {
+			return badRequest(CodeInvalidAnswer, fmt.Sprintf("question %d is not part of this polcompass", a.QuestionID))
+		}
+		if answered[a.QuestionID] {
+			return badRequest(CodeInvalidAnswer, fmt.Sprintf("question %d was answered more than once", a.QuestionID))
+		}
+		answered[a.QuestionID] = true
+
+		if a.Skipped {
+			if !scale.AllowSkip {
+				return badRequest(CodeInvalidAnswer, fmt.Sprintf("question %d cannot be skipped on this polcompass", a.QuestionID))
+			}
+			continue
+		}
+		if !scale.has(a.Value) {
+			return badRequest(CodeInvalidAnswer, fmt.Sprintf("answer to question %d must be one of %s", a.QuestionID, scale.values()))
+		}
+	}
+	return nil
+}
+
+// sortContributions scales the contributions to an axis like its score and
+// orders them by how far they moved it.
+func sortContributions(contributions []QuestionContribution, maxScore float64) []QuestionContribution {
+	for i := range contributions {
+		contributions[i].Contribution = normalize(contributions[i].Contribution, maxScore)
+	}
+	sort.SliceStable(contributions, func(i, j int) bool {
+		return math.Abs(contributions[i].Contribution) > math.Abs(contributions[j].Contribution)
+	})
+	if contributions == nil {
+		return []QuestionContribution{}
+	}
+	return contributions
+}
+
+func normalize(sum float64, maxScore float64) float64 {
+	if maxScore == 0 {
+		return 0
+	}
+	return sum / maxScore
+}
+
+func sign(direction int) int {
+	if direction > 0 {
+		return 1
+	}
+	if direction < 0 {
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,434 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CompassStore persists polcompasses and their responses. Lookups return an
+// APIError telling apart unknown ids, deleted polcompasses and backend failures.
+type CompassStore interface {
+	// Get returns a polcompass with its ordered axes and its questions.
+	Get(ctx context.Context, id uint) (Polcompass, error)
+	// First returns the polcompass with the lowest id.
+	First(ctx context.Context) (Polcompass, error)
+	// ListSummaries returns one page of the polcompasses having a name and a
+	// description, along with how many of them there are.
+	ListSummaries(ctx context.Context, page int, perPage int) ([]Summary, int64, error)
+	// Create saves a new polcompass with its Axes and Questions and reloads it.
+	Create(ctx context.Context, polcompass *Polcompass) error
+	// Update saves polcompass and replaces its axes and questions by its Axes
+	// and Questions, then reloads it. Questions are matched on their text, the
+	// ones kept keep their ids. renamed maps the old names of the renamed axes
+	// to their new ones, the reference points placed by their scores follow them.
+	Update(ctx context.Context, polcompass *Polcompass, renamed map[string]string) error
+	// Delete soft deletes a polcompass.
+	Delete(ctx context.Context, id uint) error
+	// Restore brings back a deleted polcompass.
+	Restore(ctx context.Context, id uint) (Polcompass, error)
+	// CreateResponse saves a response with its answers.
+	CreateResponse(ctx context.Context, response *Response) error
+	// GetResponse returns a response with its answers from its public id.
+	GetResponse(ctx context.Context, publicID string) (Response, error)
+	// CreateReference adds a reference point to its polcompass.
+	CreateReference(ctx context.Context, reference *ReferencePoint) error
+	// UpdateReference replaces a reference point of its polcompass.
+	UpdateReference(ctx context.Context, reference *ReferencePoint) error
+	// DeleteReference removes a reference point of a polcompass.
+	DeleteReference(ctx context.Context, polcompassID uint, id uint) error
+}
+
+// GormStore is the CompassStore backed by a gorm database.
+type GormStore struct {
+	DB *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{DB: db}
+}
+
+func (s *GormStore) Get(ctx context.Context, id uint) (Polcompass, error) {
+	db := s.DB.WithContext(ctx)
+	var polcompass Polcompass
+	err := findCompass(db, &polcompass, id)
+	if err == nil {
+		return polcompass, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return Polcompass{}, databaseError(ctx, "Error while reading the polcompass", err)
+	}
+	return Polcompass{}, missingCompass(ctx, db, id)
+}
+
+// missingCompass tells a soft-deleted polcompass, answered with a 410, from
+// one which never existed.
+func missingCompass(ctx context.Context, db *gorm.DB, id uint) error {
+	var deleted int64
+	if err := db.Unscoped().Model(&Polcompass{}).Where("id = ? AND deleted_at IS NOT NULL", id).Count(&deleted).Error; err != nil {
+		return databaseError(ctx, "Error while reading the polcompass", err)
+	}
+	if deleted > 0 {
+		return errCompassDeleted
+	}
+	return errCompassNotFound
+}
+
+func (s *GormStore) First(ctx context.Context) (Polcompass, error) {
+	db := s.DB.WithContext(ctx)
+	var polcompass Polcompass
+	err := findCompass(db, &polcompass)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Polcompass{}, errCompassNotFound
+	}
+	if err != nil {
+		return Polcompass{}, databaseError(ctx, "Error while reading the polcompass", err)
+	}
+	return polcompass, nil
+}
+
+func (s *GormStore) ListSummaries(ctx context.Context, page int, perPage int) ([]Summary, int64, error) {
+	db := s.DB.WithContext(ctx)
+	var summaries []Summary
+	err := listed(db).Order("id").Limit(perPage).Offset((page-1)*perPage).Select("id", "name", "description").Find(&summaries).Error
+	if err != nil {
+		return nil, 0, databaseError(ctx, "Error while reading the summaries", err)
+	}
+
+	var count int64
+	err = listed(db).Count(&count).Error
+	if err != nil {
+		return nil, 0, databaseError(ctx, "Error while reading the summaries", err)
+	}
+
+	return summaries, count, nil
+}
+
+func (s *GormStore) Create(ctx context.Context, polcompass *Polcompass) error {
+	db := s.DB.WithContext(ctx)
+	questions := polcompass.Questions
+	polcompass.Questions = nil
+	polcompass.References = nil
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(polcompass).Error; err != nil {
+			return err
+		}
+		return saveQuestions(tx, polcompass.ID, questions)
+	})
+	if err != nil {
+		return databaseError(ctx, "Error while saving the polcompass to the database", err)
+	}
+
+	if err := findCompass(db, polcompass, polcompass.ID); err != nil {
+		return databaseError(ctx, "Error while reading the polcompass", err)
+	}
+	return nil
+}
+
+func (s *GormStore) Update(ctx context.Context, polcompass *Polcompass, renamed map[string]string) error {
+	db := s.DB.WithContext(ctx)
+	axes, questions := polcompass.Axes, polcompass.Questions
+	polcompass.Axes = nil
+	polcompass.Questions = nil
+	polcompass.References = nil
+	for i := range axes {
+		axes[i].PolcompassID = polcompass.ID
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Save would insert a soft-deleted polcompass again, bringing it back
+		result := tx.Model(polcompass).Where("deleted_at IS NULL").Select("*").Omit("id", "created_at", "deleted_at").Updates(polcompass)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errCompassNotFound
+		}
+		if err := tx.Where("polcompass_id = ?", polcompass.ID).Delete(&Axis{}).Error; err != nil {
+			return err
+		}
+		if len(axes) > 0 {
+			if err := tx.Create(&axes).Error; err != nil {
+				return err
+			}
+		}
+		if err := syncQuestions(tx, polcompass.ID, questions); err != nil {
+			return err
+		}
+		return replotReferences(tx, polcompass.ID, renamed)
+	})
+	if errors.Is(err, errCompassNotFound) {
+		return errCompassNotFound
+	}
+	if err != nil {
+		return databaseError(ctx, "Error while saving the polcompass to the database", err)
+	}
+
+	if err := findCompass(db, polcompass, polcompass.ID); err != nil {
+		return databaseError(ctx, "Error while reading the polcompass", err)
+	}
+	return nil
+}
+
+func (s *GormStore) Delete(ctx context.Context, id uint) error {
+	db := s.DB.WithContext(ctx)
+	result := db.Delete(&Polcompass{}, id)
+	if result.Error != nil {
+		return databaseError(ctx, "Error while deleting the polcompass", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return missingCompass(ctx, db, id)
+	}
+	return nil
+}
+
+func (s *GormStore) Restore(ctx context.Context, id uint) (Polcompass, error) {
+	db := s.DB.WithContext(ctx)
+	var polcompass Polcompass
+	err := db.Unscoped().Where("deleted_at IS NOT NULL").First(&polcompass, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Polcompass{}, errNoDeletedCompass
+	}
+
+	if err == nil {
+		err = db.Unscoped().Model(&polcompass).Update("deleted_at", nil).Error
+	}
+	if err == nil {
+		err = findCompass(db, &polcompass, id)
+	}
+	if err != nil {
+		return Polcompass{}, databaseError(ctx, "Error while restoring the polcompass", err)
+	}
+
+	return polcompass, nil
+}
+
+func (s *GormStore) CreateResponse(ctx context.Context, response *Response) error {
+	db := s.DB.WithContext(ctx)
+	if err := db.Create(response).Error; err != nil {
+		return databaseError(ctx, "Error while saving the response", err)
+	}
+	return nil
+}
+
+func (s *GormStore) GetResponse(ctx context.Context, publicID string) (Response, error) {
+	db := s.DB.WithContext(ctx)
+	var response Response
+	err := db.Preload("Answers").Where("public_id = ?", publicID).First(&response).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Response{}, errResponseNotFound
+	}
+	if err != nil {
+		return Response{}, databaseError(ctx, "Error while reading the response", err)
+	}
+	return response, nil
+}
+
+func (s *GormStore) CreateReference(ctx context.Context, reference *ReferencePoint) error {
+	db := s.DB.WithContext(ctx)
+	if err := db.Omit("Answers.Question").Create(reference).Error; err != nil {
+		return databaseError(ctx, "Error while saving the reference point", err)
+	}
+	return nil
+}
+
+func (s *GormStore) UpdateReference(ctx context.Context, reference *ReferencePoint) error {
+	db := s.DB.WithContext(ctx)
+	var existing ReferencePoint
+	err := db.Where("polcompass_id = ?", reference.PolcompassID).First(&existing, reference.ID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errReferenceNotFound
+	}
+	if err == nil {
+		reference.CreatedAt = existing.CreatedAt
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Omit("Answers").Save(reference).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("reference_point_id = ?", reference.ID).Delete(&ReferenceAnswer{}).Error; err != nil {
+				return err
+			}
+			for i := range reference.Answers {
+				reference.Answers[i].ID = 0
+				reference.Answers[i].ReferencePointID = reference.ID
+			}
+			if len(reference.Answers) == 0 {
+				return nil
+			}
+			return tx.Omit("Question").Create(&reference.Answers).Error
+		})
+	}
+	if err != nil {
+		return databaseError(ctx, "Error while saving the reference point", err)
+	}
+	return nil
+}
+
+func (s *GormStore) DeleteReference(ctx context.Context, polcompassID uint, id uint) error {
+	db := s.DB.WithContext(ctx)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("polcompass_id = ?", polcompassID).Delete(&ReferencePoint{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errReferenceNotFound
+		}
+		// sqlite only enforces the cascade with foreign keys enabled
+		return tx.Where("reference_point_id = ?", id).Delete(&ReferenceAnswer{}).Error
+	})
+	if errors.Is(err, errReferenceNotFound) {
+		return errReferenceNotFound
+	}
+	if err != nil {
+		return databaseError(ctx, "Error while deleting the reference point", err)
+	}
+	return nil
+}
+
+// listed selects the polcompasses shown in the summaries, those having a
+// name and a description.
+func listed(db *gorm.DB) *gorm.DB {
+	return db.Model(&Polcompass{}).Where("name <> ? AND description <> ?", "", "")
+}
+
+// saveQuestions upserts the questions of a polcompass, keyed on the question
+// text. Postgres and sqlite update the conflicting row through excluded.column,
+// mysql has no conflict target and updates it through VALUES(column).
+func saveQuestions(db *gorm.DB, polcompassID uint, questions []Question) error {
+	if len(questions) == 0 {
+		return nil
+	}
+
+	var questionsToSave []Question
+
+	for _, q := range questions {
+		questionsToSave = append(questionsToSave, prepareQuestion(q, polcompassID))
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "question"}, {Name: "polcompass_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"direction", "affects", "effects", "polcompass_id"}),
+	}).Create(&questionsToSave).Error
+}
+
+// syncQuestions makes the questions of a polcompass match questions, keyed on
+// the question text. The questions kept keep their ids so the answers pointing
+// at them stay attached, only the changed ones are written and the questions
+// missing from the new set are deleted.
+func syncQuestions(db *gorm.DB, polcompassID uint, questions []Question) error {
+	var existing []Question
+	if err := db.Where("polcompass_id = ?", polcompassID).Find(&existing).Error; err != nil {
+		return err
+	}
+	byText := make(map[string]Question, len(existing))
+	for _, q := range existing {
+		byText[q.Question] = q
+	}
+
+	questions = uniqueQuestions(questions)
+	kept := make(map[string]bool, len(questions))
+	var changed []Question
+	for _, q := range questions {
+		kept[q.Question] = true
+		if old, isPresent := byText[q.Question]; isPresent && sameQuestion(old, prepareQuestion(q, polcompassID)) {
+			continue
+		}
+		changed = append(changed, q)
+	}
+
+	var removed []uint
+	for _, q := range existing {
+		if !kept[q.Question] {
+			removed = append(removed, q.ID)
+		}
+	}
+	if len(removed) > 0 {
+		// sqlite only enforces the cascade with foreign keys enabled
+		if err := db.Where("question_id IN ?", removed).Delete(&ReferenceAnswer{}).Error; err != nil {
+			return err
+		}
+		if err := db.Delete(&Question{}, removed).Error; err != nil {
+			return err
+		}
+	}
+
+	return saveQuestions(db, polcompassID, changed)
+}
+
+// replotReferences moves the reference points of a polcompass to where its
+// saved questions, axes and scale put them, following the renamed axes.
+func replotReferences(db *gorm.DB, polcompassID uint, renamed map[string]string) error {
+	var polcompass Polcompass
+	if err := findCompass(db, &polcompass, polcompassID); err != nil {
+		return err
+	}
+	for _, reference := range polcompass.replot(renamed) {
+		if err := db.Model(&reference).Select("Field1", "Field2", "Scores").Updates(&reference).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uniqueQuestions merges the questions sharing a text, the last one wins and
+// takes the place of the first.
+func uniqueQuestions(questions []Question) []Question {
+	var unique []Question
+	byText := make(map[string]int, len(questions))
+	for _, q := range questions {
+		if i, isPresent := byText[q.Question]; isPresent {
+			unique[i] = q
+			continue
+		}
+		byText[q.Question] = len(unique)
+		unique = append(unique, q)
+	}
+	return unique
+}
+
+// sameQuestion reports whether saving b over a would change nothing.
+func sameQuestion(a Question, b Question) bool {
+	if a.Affects != b.Affects || a.Direction != b.Direction || len(a.Effects) != len(b.Effects) {
+		return false
+	}
+	for i := range a.Effects {
+		if a.Effects[i] != b.Effects[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// prepareQuestion attaches q to a polcompass and mirrors its first effect
+// on the legacy Affects/Direction columns.
+func prepareQuestion(q Question, polcompassID uint) Question {
+	q.ID = 0
+	q.PolcompassID = polcompassID
+	if len(q.Effects) > 0 {
+		q.Affects = q.Effects[0].Axis
+		q.Direction = weightSign(q.Effects[0].Weight)
+	}
+	return q
+}
+
+// databaseError logs err, which clients never see, and returns the
+// internal error answered to them.
+func databaseError(ctx context.Context, message string, err error) *APIError {
+	slog.ErrorContext(ctx, message, "error", err)
+	return internalError(message)
+}
+
+var (
+	errCompassNotFound   = notFound(CodeCompassNotFound, "PolCompass not found")
+	errCompassDeleted    = NewAPIError(http.StatusGone, CodeCompassDeleted, "This PolCompass was deleted")
+	errNoDeletedCompass  = notFound(CodeCompassNotFound, "No deleted PolCompass with this id")
+	errResponseNotFound  = notFound(CodeResponseNotFound, "Response not found")
+	errReferenceNotFound = notFound(CodeReferenceNotFound, "Reference point not found")
+)
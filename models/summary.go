@@ -20,14 +20,9 @@ type Summary struct {
 }
 
 func (p *PolCompassController) Summary(c *gin.Context) {
-	var summaries []Summary
-
 	perPage, isPresent := c.GetQuery("perPage")
 	if !isPresent {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "Please specify how many items you want to see per page",
-		},
-		)
+		respondError(c, badRequest(CodeMissingParameter, "Please specify how many items you want to see per page"))
 		return
 	}
 
@@ -39,37 +34,30 @@ func (p *PolCompassController) Summary(c *gin.Context) {
 
 	perPageInt, err := strconv.Atoi(perPage)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "perPage must be a number",
-		})
+		respondError(c, badRequest(CodeInvalidParameter, "perPage must be a number"))
 		return
 	}
 
 	if perPageInt <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "perPage must be greater than 0",
-		})
+		respondError(c, badRequest(CodeInvalidParameter, "perPage must be greater than 0"))
 		return
 	}
 	pageInt, err := strconv.Atoi(page)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "page must be a number",
-		})
+		respondError(c, badRequest(CodeInvalidParameter, "page must be a number"))
 		return
 	}
 
 	if pageInt <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"message": "page must be greater than 0",
-		})
+		respondError(c, badRequest(CodeInvalidParameter, "page must be greater than 0"))
 		return
 	}
 
-	p.DB.Model(&Polcompass{}).Limit(perPageInt).Offset((pageInt - 1) * perPageInt).Select("id, name, description").Where("name != '' and description != ''").Find(&summaries)
-
-	var num_summaries int64
-	p.DB.Model(&Polcompass{}).Select("id, name, description").Where("name != '' and description != ''").Count(&num_summaries)
+	summaries, num_summaries, err := p.Store.ListSummaries(c.Request.Context(), pageInt, perPageInt)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
 	numberOfPages := int(math.Ceil(float64(num_summaries) / float64(perPageInt)))
 
 	summariesResponse := SummaryResponse{
@@ -0,0 +1,202 @@
+package models
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PolCompassPatchReq holds a partial edit of a polcompass, nil fields are left untouched.
+type PolCompassPatchReq struct {
+	Field1Name  *string     `json:"field1_name" validate:"omitnil,min=1"`
+	Field2Name  *string     `json:"field2_name" validate:"omitnil,min=1"`
+	Name        *string     `json:"name" validate:"omitnil,min=1"`
+	Description *string     `json:"description"`
+	Axes        *[]Axis     `json:"axes" validate:"omitnil,min=1,dive"`
+	Questions   *[]Question `json:"questions" validate:"omitnil,dive"`
+	Scale       *Scale      `json:"scale"`
+	Regions     *[]Region   `json:"regions" validate:"omitnil,dive"`
+}
+
+func (p *PolCompassController) PUT(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req PolCompassReq
+	err := json.NewDecoder(c.Request.Body).Decode(&req)
+	if err != nil {
+		respondError(c, badRequest(CodeInvalidBody, "Bad request for polcompass request field1_name string,field2_name string, questions [] "))
+		return
+	}
+
+	if !validateRequest(c, req, unknownAxes(req.axes(), req.Questions)...) {
+		return
+	}
+
+	polcompass, err := p.Store.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	polcompass.Name = req.Name
+	polcompass.Description = req.Description
+	polcompass.Scale = req.scale()
+	polcompass.Regions = req.Regions
+
+	p.replace(c, &polcompass, req.axes(), req.Questions, nil)
+}
+
+func (p *PolCompassController) PATCH(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var req PolCompassPatchReq
+	err := json.NewDecoder(c.Request.Body).Decode(&req)
+	if err != nil {
+		respondError(c, badRequest(CodeInvalidBody, "Bad request for polcompass request field1_name string,field2_name string, questions [] "))
+		return
+	}
+
+	polcompass, err := p.Store.Get(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	axes := polcompass.axisList()
+	if req.Axes != nil {
+		axes = *req.Axes
+	}
+	renamed := make(map[string]string)
+	if req.Field1Name != nil {
+		axes = renameAxis(axes, renamed, 0, *req.Field1Name)
+	}
+	if req.Field2Name != nil {
+		axes = renameAxis(axes, renamed, 1, *req.Field2Name)
+	}
+
+	if req.Name != nil {
+		polcompass.Name = *req.Name
+	}
+	if req.Description != nil {
+		polcompass.Description = *req.Description
+	}
+	if req.Scale != nil {
+		polcompass.Scale = *req.Scale
+	}
+	if req.Regions != nil {
+		polcompass.Regions = *req.Regions
+	}
+
+	// the questions kept follow their axes when they are renamed
+	questions := renameEffects(polcompass.Questions, renamed)
+	if req.Questions != nil {
+		questions = *req.Questions
+	}
+
+	// the questions are checked against the axes the polcompass will have
+	if !validateRequest(c, req, unknownAxes(axes, questions)...) {
+		return
+	}
+
+	p.replace(c, &polcompass, axes, questions, renamed)
+}
+
+// renameAxis renames the axis at position, adding it when the polcompass has
+// fewer axes, and records the old name of the axis in renamed.
+func renameAxis(axes []Axis, renamed map[string]string, position int, name string) []Axis {
+	result := make([]Axis, len(axes))
+	copy(result, axes)
+	for len(result) <= position {
+		result = append(result, Axis{})
+	}
+	if old := result[position].Name; old != "" && old != name {
+		renamed[old] = name
+	}
+	result[position].Name = name
+	return result
+}
+
+// renameEffects returns copies of questions whose effects on the renamed
+// axes point at their new names.
+func renameEffects(questions []Question, renamed map[string]string) []Question {
+	if len(renamed) == 0 {
+		return questions
+	}
+	result := make([]Question, len(questions))
+	for i, q := range questions {
+		if name, isPresent := renamed[q.Affects]; isPresent {
+			q.Affects = name
+		}
+		effects := make([]Effect, len(q.Effects))
+		for j, e := range q.Effects {
+			if name, isPresent := renamed[e.Axis]; isPresent {
+				e.Axis = name
+			}
+			effects[j] = e
+		}
+		if q.Effects != nil {
+			q.Effects = effects
+		}
+		result[i] = q
+	}
+	return result
+}
+
+// replace saves polcompass and swaps its axes and question set,
+// recounting the questions of each axis. renamed maps the old names of the
+// renamed axes to their new ones.
+func (p *PolCompassController) replace(c *gin.Context, polcompass *Polcompass, axes []Axis, questions []Question, renamed map[string]string) {
+	if err := countQuestions(axes, questions); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	polcompass.setAxes(axes)
+	polcompass.Questions = questions
+
+	if err := p.Store.Update(c.Request.Context(), polcompass, renamed); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, polcompass)
+}
+
+func (p *PolCompassController) DELETE(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := p.Store.Delete(c.Request.Context(), id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Deleted successfully",
+	})
+}
+
+// Restore brings back a polcompass removed by DELETE.
+func (p *PolCompassController) Restore(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	polcompass, err := p.Store.Restore(c.Request.Context(), id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, polcompass)
+}
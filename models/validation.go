@@ -0,0 +1,179 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes one invalid field of a request, Field is the json path
+// of the value, for example questions[3].direction.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	v.RegisterStructValidation(validatePolCompassReq, PolCompassReq{})
+	v.RegisterStructValidation(validatePolCompassPatchReq, PolCompassPatchReq{})
+	v.RegisterStructValidation(validateScale, Scale{})
+	v.RegisterStructValidation(validateRegion, Region{})
+	return v
+}
+
+func validatePolCompassReq(sl validator.StructLevel) {
+	req := sl.Current().Interface().(PolCompassReq)
+	reportDuplicates(sl, req.Axes, req.Questions)
+}
+
+func validatePolCompassPatchReq(sl validator.StructLevel) {
+	req := sl.Current().Interface().(PolCompassPatchReq)
+	var axes []Axis
+	if req.Axes != nil {
+		axes = *req.Axes
+	}
+	var questions []Question
+	if req.Questions != nil {
+		questions = *req.Questions
+	}
+	reportDuplicates(sl, axes, questions)
+}
+
+// validateScale flags the options repeating the value or the label of an
+// earlier option.
+func validateScale(sl validator.StructLevel) {
+	scale := sl.Current().Interface().(Scale)
+	values := make(map[int]bool, len(scale.Options))
+	labels := make(map[string]bool, len(scale.Options))
+	for i, option := range scale.Options {
+		if values[option.Value] {
+			sl.ReportError(option.Value, fmt.Sprintf("options[%d].value", i), "Value", "unique", "")
+		}
+		if option.Label != "" && labels[option.Label] {
+			sl.ReportError(option.Label, fmt.Sprintf("options[%d].label", i), "Label", "unique", "")
+		}
+		values[option.Value] = true
+		labels[option.Label] = true
+	}
+}
+
+// validateRegion checks that a region has exactly one shape.
+func validateRegion(sl validator.StructLevel) {
+	region := sl.Current().Interface().(Region)
+	shapes := 0
+	if region.Quadrant != "" {
+		shapes++
+	}
+	if region.Rectangle != nil {
+		shapes++
+	}
+	if len(region.Polygon) > 0 {
+		shapes++
+	}
+	if shapes != 1 {
+		sl.ReportError(region.Quadrant, "quadrant", "Quadrant", "shape", "")
+	}
+}
+
+// reportDuplicates flags every axis name and question text already used
+// earlier in the same request.
+func reportDuplicates(sl validator.StructLevel, axes []Axis, questions []Question) {
+	axisNames := make(map[string]bool, len(axes))
+	for i, axis := range axes {
+		if axis.Name != "" && axisNames[axis.Name] {
+			sl.ReportError(axis.Name, fmt.Sprintf("axes[%d].name", i), "Name", "unique", "")
+		}
+		axisNames[axis.Name] = true
+	}
+
+	questionTexts := make(map[string]bool, len(questions))
+	for i, q := range questions {
+		if q.Question != "" && questionTexts[q.Question] {
+			sl.ReportError(q.Question, fmt.Sprintf("questions[%d].question", i), "Question", "unique", "")
+		}
+		questionTexts[q.Question] = true
+	}
+}
+
+// validateRequest checks the validate tags of req and writes a 400 listing every
+// invalid field along with extra, the errors found outside of the tags. It
+// returns false when the request was rejected.
+func validateRequest(c *gin.Context, req interface{}, extra ...FieldError) bool {
+	var fieldErrors []FieldError
+	err := validate.Struct(req)
+
+	var validationErrors validator.ValidationErrors
+	if err != nil && !errors.As(err, &validationErrors) {
+		respondError(c, badRequest(CodeInvalidBody, err.Error()))
+		return false
+	}
+
+	for _, e := range validationErrors {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   fieldPath(e.Namespace()),
+			Rule:    e.Tag(),
+			Message: fieldMessage(e),
+		})
+	}
+	fieldErrors = append(fieldErrors, extra...)
+	if len(fieldErrors) == 0 {
+		return true
+	}
+
+	respondError(c, invalidFields(fieldErrors))
+	return false
+}
+
+// invalidFields is the 400 listing the invalid fields of a request.
+func invalidFields(fieldErrors []FieldError) *APIError {
+	return badRequest(CodeValidationFailed, "The request contains invalid fields").WithDetails(fieldErrors)
+}
+
+// fieldPath removes the struct name from a validator namespace.
+func fieldPath(namespace string) string {
+	_, path, found := strings.Cut(namespace, ".")
+	if !found {
+		return namespace
+	}
+	return path
+}
+
+func fieldMessage(e validator.FieldError) string {
+	switch e.Tag() {
+	case "required", "required_without":
+		return "is required"
+	case "oneof":
+		return "must be one of " + e.Param()
+	case "nefield":
+		return "must not be the same as another field"
+	case "min":
+		return "must have a length of at least " + e.Param()
+	case "max":
+		return "must have a length of at most " + e.Param()
+	case "unique":
+		return "is already used earlier in the request"
+	case "shape":
+		return "a region needs exactly one of quadrant, rectangle or polygon"
+	case "excluded_with":
+		return "must not be set along with " + e.Param()
+	case "gtfield":
+		return "must be greater than " + e.Param()
+	default:
+		return "failed the " + e.Tag() + " rule"
+	}
+}
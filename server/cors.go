@@ -0,0 +1,27 @@
+package server
+
+import (
+	"polcompass/backend/config"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// CORS returns the middleware answering the CORS policy of cfg, which
+// config.Load has validated.
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	corsConfig := cors.Config{
+		AllowMethods:     cfg.AllowMethods,
+		AllowHeaders:     cfg.AllowHeaders,
+		ExposeHeaders:    cfg.ExposeHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge.Duration,
+	}
+	if len(cfg.AllowOrigins) == 1 && cfg.AllowOrigins[0] == "*" {
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOrigins = cfg.AllowOrigins
+		corsConfig.AllowWildcard = true
+	}
+	return cors.New(corsConfig)
+}
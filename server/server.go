@@ -0,0 +1,63 @@
+// Package server runs the HTTP server and stops it gracefully.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"polcompass/backend/config"
+)
+
+// New returns the http.Server serving handler on cfg.Addr with the timeouts of cfg.
+func New(handler http.Handler, cfg config.ServerConfig) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout.Duration,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout.Duration,
+		WriteTimeout:      cfg.WriteTimeout.Duration,
+		IdleTimeout:       cfg.IdleTimeout.Duration,
+	}
+}
+
+// Run listens on srv.Addr and serves until ctx is done, see Serve.
+func Run(ctx context.Context, srv *http.Server, cfg config.ServerConfig) error {
+	listener, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	return Serve(ctx, srv, listener, cfg)
+}
+
+// Serve serves on listener until ctx is done, then stops accepting connections
+// and waits up to cfg.ShutdownTimeout for the requests in flight to finish.
+func Serve(ctx context.Context, srv *http.Server, listener net.Listener, cfg config.ServerConfig) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("listening", "addr", listener.Addr().String())
+		serveErr <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down, waiting for the requests in flight", "timeout", cfg.ShutdownTimeout.Duration.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout.Duration)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+		return fmt.Errorf("requests were still running after %s: %w", cfg.ShutdownTimeout.Duration, err)
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"polcompass/backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type AxisTestSuite struct {
+	suite.Suite
+	DB         *gorm.DB
+	controller *PolCompassController
+	router     *gin.Engine
+}
+
+func (suite *AxisTestSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+}
+
+func (suite *AxisTestSuite) SetupTest() {
+	suite.DB = openTestDB(suite.T(), testDatabase{})
+
+	suite.controller = models.NewPolCompassController(models.NewGormStore(suite.DB))
+	suite.router = gin.New()
+
+	suite.router.GET("/polcompass", suite.controller.GET)
+	suite.router.POST("/polcompass", suite.controller.POST)
+	suite.router.POST("/polcompass/:id/score", suite.controller.Score)
+}
+
+func (suite *AxisTestSuite) TestPOST_FourAxes() {
+	requestBody := PolCompassReq{
+		Name:        "8values",
+		Description: "Four axes",
+		Axes: []Axis{
+			{Name: "economic", NegativeLabel: "Equality", PositiveLabel: "Markets"},
+			{Name: "diplomatic", NegativeLabel: "Nation", PositiveLabel: "Globe"},
+			{Name: "civil", NegativeLabel: "Liberty", PositiveLabel: "Authority"},
+			{Name: "society", NegativeLabel: "Tradition", PositiveLabel: "Progress"},
+		},
+		Questions: []Question{
+			{Question: "Economic Question", Affects: "economic", Direction: 1},
+			{Question: "Diplomatic Question", Affects: "diplomatic", Direction: 1},
+			{Question: "Civil Question 1", Affects: "civil", Direction: -1},
+			{Question: "Civil Question 2", Affects: "civil", Direction: 1},
+			{Question: "Society Question", Affects: "society", Direction: -1},
+		},
+	}
+
+	w := serveJSON(suite.router, "POST", "/polcompass", requestBody)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	req, _ := http.NewRequest("GET", "/polcompass?id=1", nil)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	var response Polcompass
+	json.Unmarshal(w.Body.Bytes(), &response)
+	suite.Require().Len(response.Axes, 4)
+	assert.Equal(suite.T(), "economic", response.Axes[0].Name)
+	assert.Equal(suite.T(), "Equality", response.Axes[0].NegativeLabel)
+	assert.Equal(suite.T(), "Markets", response.Axes[0].PositiveLabel)
+	assert.Equal(suite.T(), "society", response.Axes[3].Name)
+	assert.Equal(suite.T(), 2, response.Axes[2].QuestionQty)
+
+	// The legacy fields mirror the first two axes
+	assert.Equal(suite.T(), "economic", response.Field1Name)
+	assert.Equal(suite.T(), "diplomatic", response.Field2Name)
+	assert.Equal(suite.T(), 1, response.Field1QuestionQty)
+	assert.Equal(suite.T(), 1, response.Field2QuestionQty)
+
+	var answers []AnswerReq
+	for _, q := range response.Questions {
+		answers = append(answers, AnswerReq{QuestionID: q.ID, Value: 2})
+	}
+	w = serveJSON(suite.router, "POST", "/polcompass/1/score", ScoreReq{Answers: answers})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var score ScoreResponse
+	json.Unmarshal(w.Body.Bytes(), &score)
+	assert.Equal(suite.T(), []AxisScore{
+		{Name: "economic", Score: 1},
+		{Name: "diplomatic", Score: 1},
+		{Name: "civil", Score: 0},
+		{Name: "society", Score: -1},
+	}, score.Axes)
+	assert.Equal(suite.T(), 1.0, score.Field1)
+	assert.Equal(suite.T(), 1.0, score.Field2)
+}
+
+func (suite *AxisTestSuite) TestPOST_LegacyFieldsCreateAxes() {
+	requestBody := PolCompassReq{
+		Field1Name:  "Economic",
+		Field2Name:  "Social",
+		Name:        "Legacy Compass",
+		Description: "Two fields",
+		Questions: []Question{
+			{Question: "Economic Question", Affects: "Economic", Direction: 1},
+		},
+	}
+
+	w := serveJSON(suite.router, "POST", "/polcompass", requestBody)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var axes []Axis
+	suite.DB.Order("position").Find(&axes)
+	suite.Require().Len(axes, 2)
+	assert.Equal(suite.T(), "Economic", axes[0].Name)
+	assert.Equal(suite.T(), 1, axes[0].QuestionQty)
+	assert.Equal(suite.T(), "Social", axes[1].Name)
+}
+
+func (suite *AxisTestSuite) TestPOST_DuplicateAxis() {
+	requestBody := PolCompassReq{
+		Name:        "Duplicate Axes",
+		Description: "Duplicate Axes",
+		Axes:        []Axis{{Name: "economic"}, {Name: "economic"}},
+	}
+
+	w := serveJSON(suite.router, "POST", "/polcompass", requestBody)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func TestAxisSuite(t *testing.T) {
+	suite.Run(t, new(AxisTestSuite))
+}
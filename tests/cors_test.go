@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"polcompass/backend/config"
+	"polcompass/backend/server"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func corsRouter(t *testing.T, origins string, credentials string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("FEATURE_IN_MEMORY", "true")
+	t.Setenv("CORS_ALLOW_ORIGINS", origins)
+	t.Setenv("CORS_ALLOW_CREDENTIALS", credentials)
+
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(server.CORS(cfg.CORS))
+	router.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+	return router
+}
+
+func corsRequest(router *gin.Engine, method string, origin string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, "/ping", nil)
+	req.Header.Set("Origin", origin)
+	if method == http.MethodOptions {
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "Content-Type, X-Request-ID")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORS_AnyOriginWithoutCredentials(t *testing.T) {
+	router := corsRouter(t, "*", "false")
+
+	w := corsRequest(router, http.MethodGet, "https://anywhere.example")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCORS_WildcardSubdomains(t *testing.T) {
+	router := corsRouter(t, "http://localhost:5173, https://*.polcompass.example", "true")
+
+	for _, origin := range []string{"http://localhost:5173", "https://app.polcompass.example", "https://preview.app.polcompass.example"} {
+		w := corsRequest(router, http.MethodGet, origin)
+		assert.Equal(t, http.StatusOK, w.Code, origin)
+		assert.Equal(t, origin, w.Header().Get("Access-Control-Allow-Origin"), origin)
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"), origin)
+	}
+
+	w := corsRequest(router, http.MethodOptions, "https://app.polcompass.example")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "X-Request-Id")
+	assert.Equal(t, "43200", w.Header().Get("Access-Control-Max-Age"))
+
+	for _, origin := range []string{"https://polcompass.example", "http://app.polcompass.example", "https://evilpolcompass.example", "https://localhost:5173"} {
+		w := corsRequest(router, http.MethodGet, origin)
+		assert.Equal(t, http.StatusForbidden, w.Code, origin)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), origin)
+	}
+}
+
+func TestConfig_CORSValidation(t *testing.T) {
+	t.Setenv("FEATURE_IN_MEMORY", "true")
+
+	tests := []struct {
+		origins     string
+		credentials string
+		message     string
+	}{
+		{"*", "true", "cors.allow_credentials"},
+		{"http://localhost:5173,*", "false", "cannot mix"},
+		{"localhost:5173", "false", "must start with http:// or https://"},
+		{"https://polcompass.example/app", "false", "without a path"},
+		{"https://app.*.example", "false", "wildcard"},
+		{"https://*.", "false", "wildcard"},
+	}
+	for _, test := range tests {
+		t.Setenv("CORS_ALLOW_ORIGINS", test.origins)
+		t.Setenv("CORS_ALLOW_CREDENTIALS", test.credentials)
+
+		_, err := config.Load("")
+		if assert.Error(t, err, test.origins) {
+			assert.Contains(t, err.Error(), test.message, test.origins)
+		}
+	}
+
+	t.Setenv("CORS_ALLOW_ORIGINS", "https://*.polcompass.example")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+	t.Setenv("CORS_ALLOW_METHODS", "GET,FETCH")
+	_, err := config.Load("")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cors.allow_methods[1]")
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"polcompass/backend/config"
+	"polcompass/backend/database"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// flakyDialector fails to initialize until failures reaches zero, like a
+// database that is still starting.
+type flakyDialector struct {
+	gorm.Dialector
+	failures int
+	attempts int
+}
+
+func (d *flakyDialector) Initialize(db *gorm.DB) error {
+	d.attempts++
+	if d.failures > 0 {
+		d.failures--
+		return errors.New("connection refused")
+	}
+	return d.Dialector.Initialize(db)
+}
+
+func retryConfig(timeout time.Duration) config.DatabaseConfig {
+	cfg := config.Default().Database
+	cfg.ConnectTimeout = config.Duration{Duration: timeout}
+	cfg.RetryInitialDelay = config.Duration{Duration: time.Millisecond}
+	cfg.RetryMaxDelay = config.Duration{Duration: 4 * time.Millisecond}
+	cfg.MaxOpenConns = 3
+	return cfg
+}
+
+func TestConnect_RetriesUntilAvailable(t *testing.T) {
+	dialector := &flakyDialector{Dialector: sqlite.Open(":memory:"), failures: 3}
+
+	db, err := database.Connect(context.Background(), dialector, retryConfig(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 4, dialector.attempts)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	assert.Equal(t, 3, sqlDB.Stats().MaxOpenConnections)
+}
+
+func TestConnect_GivesUpAfterDeadline(t *testing.T) {
+	dialector := &flakyDialector{Dialector: sqlite.Open(":memory:"), failures: 1 << 30}
+
+	start := time.Now()
+	_, err := database.Connect(context.Background(), dialector, retryConfig(30*time.Millisecond))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection refused")
+	assert.Greater(t, dialector.attempts, 1)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestBackoff(t *testing.T) {
+	initial, maxDelay := 100*time.Millisecond, time.Second
+
+	for attempt, expected := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 5: time.Second, 20: time.Second} {
+		for i := 0; i < 20; i++ {
+			delay := database.Backoff(attempt, initial, maxDelay)
+			assert.GreaterOrEqual(t, delay, expected/2)
+			assert.LessOrEqual(t, delay, expected)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"polcompass/backend/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type EffectTestSuite struct {
+	suite.Suite
+	DB         *gorm.DB
+	controller *PolCompassController
+	router     *gin.Engine
+}
+
+func (suite *EffectTestSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+}
+
+func (suite *EffectTestSuite) SetupTest() {
+	suite.DB = openTestDB(suite.T(), testDatabase{})
+
+	suite.controller = models.NewPolCompassController(models.NewGormStore(suite.DB))
+	suite.router = gin.New()
+
+	suite.router.POST("/polcompass", suite.controller.POST)
+	suite.router.POST("/polcompass/:id/score", suite.controller.Score)
+}
+
+func (suite *EffectTestSuite) TestPOST_WeightedEffects() {
+	requestBody := PolCompassReq{
+		Field1Name:  "Economic",
+		Field2Name:  "Social",
+		Name:        "Weighted Compass",
+		Description: "Questions moving several axes",
+		Questions: []Question{
+			{Question: "Taxation is theft", Effects: []Effect{{Axis: "Economic", Weight: 1}, {Axis: "Social", Weight: -0.5}}},
+			{Question: "Rent control is a good idea", Affects: "Economic", Direction: -1},
+			{Question: "Drugs should be legal", Affects: "Social", Direction: -1},
+		},
+	}
+
+	w := serveJSON(suite.router, "POST", "/polcompass", requestBody)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var savedPolcompass Polcompass
+	suite.DB.Preload("Questions").First(&savedPolcompass)
+	assert.Equal(suite.T(), 2, savedPolcompass.Field1QuestionQty)
+	assert.Equal(suite.T(), 2, savedPolcompass.Field2QuestionQty)
+
+	questions := make(map[string]Question)
+	for _, q := range savedPolcompass.Questions {
+		questions[q.Question] = q
+	}
+	taxation := questions["Taxation is theft"]
+	assert.Equal(suite.T(), "Economic", taxation.Affects)
+	assert.Equal(suite.T(), 1, taxation.Direction)
+	assert.Equal(suite.T(), []Effect{{Axis: "Economic", Weight: 1}, {Axis: "Social", Weight: -0.5}}, taxation.Effects)
+
+	w = serveJSON(suite.router, "POST", "/polcompass/1/score", ScoreReq{Answers: []AnswerReq{
+		{QuestionID: taxation.ID, Value: 2},
+		{QuestionID: questions["Drugs should be legal"].ID, Value: -2},
+	}})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var score ScoreResponse
+	json.Unmarshal(w.Body.Bytes(), &score)
+	// Economic : 1*2 out of (1+1)*2, Social : (-0.5*2 + -1*-2) out of (0.5+1)*2
+	assert.InDelta(suite.T(), 0.5, score.Field1, 1e-9)
+	assert.InDelta(suite.T(), 1.0/3.0, score.Field2, 1e-9)
+}
+
+func (suite *EffectTestSuite) TestPOST_EffectOnUnknownAxis() {
+	requestBody := PolCompassReq{
+		Field1Name:  "Economic",
+		Field2Name:  "Social",
+		Name:        "Weighted Compass",
+		Description: "Questions moving several axes",
+		Questions: []Question{
+			{Question: "Taxation is theft", Effects: []Effect{{Axis: "Economic", Weight: 1}, {Axis: "Diplomatic", Weight: 1}}},
+		},
+	}
+
+	w := serveJSON(suite.router, "POST", "/polcompass", requestBody)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	var response struct {
+		Code   string       `json:"code"`
+		Errors []FieldError `json:"details"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(suite.T(), "validation_failed", response.Code)
+	suite.Require().Len(response.Errors, 1)
+	assert.Equal(suite.T(), "questions[0].effects[1].axis", response.Errors[0].Field)
+	assert.Equal(suite.T(), "axis", response.Errors[0].Rule)
+}
+
+func TestEffectSuite(t *testing.T) {
+	suite.Run(t, new(EffectTestSuite))
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"polcompass/backend/health"
+	"polcompass/backend/migrations"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type HealthTestSuite struct {
+	suite.Suite
+	DB     *gorm.DB
+	router *gin.Engine
+}
+
+func (suite *HealthTestSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+}
+
+func (suite *HealthTestSuite) SetupTest() {
+	var err error
+	suite.DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	handler := health.NewHandler(health.DatabaseCheck(suite.DB), health.MigrationsCheck(suite.DB))
+	suite.router = gin.New()
+	suite.router.GET("/healthz", handler.Healthz)
+	suite.router.GET("/readyz", handler.Readyz)
+}
+
+func (suite *HealthTestSuite) TearDownTest() {
+	sqlDB, _ := suite.DB.DB()
+	sqlDB.Close()
+}
+
+func (suite *HealthTestSuite) readyz() (int, health.ReadinessResponse) {
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	var response health.ReadinessResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w.Code, response
+}
+
+func (suite *HealthTestSuite) TestReady() {
+	_, err := migrations.New(suite.DB).Up()
+	suite.Require().NoError(err)
+
+	code, response := suite.readyz()
+	assert.Equal(suite.T(), http.StatusOK, code)
+	assert.Equal(suite.T(), "ok", response.Status)
+	assert.Equal(suite.T(), "ok", response.Dependencies["database"].Status)
+	assert.Equal(suite.T(), "ok", response.Dependencies["migrations"].Status)
+	assert.EqualValues(suite.T(), migrations.New(suite.DB).Latest(), response.Dependencies["migrations"].Details["version"])
+}
+
+func (suite *HealthTestSuite) TestPendingMigrations() {
+	_, err := (&migrations.Migrator{DB: suite.DB, Migrations: migrations.All[:1]}).Up()
+	suite.Require().NoError(err)
+
+	code, response := suite.readyz()
+	assert.Equal(suite.T(), http.StatusServiceUnavailable, code)
+	assert.Equal(suite.T(), "unavailable", response.Status)
+	assert.Equal(suite.T(), "ok", response.Dependencies["database"].Status)
+	assert.Equal(suite.T(), "unavailable", response.Dependencies["migrations"].Status)
+	assert.Contains(suite.T(), response.Dependencies["migrations"].Error, "version 1")
+}
+
+func (suite *HealthTestSuite) TestDatabaseDown() {
+	sqlDB, _ := suite.DB.DB()
+	sqlDB.Close()
+
+	code, response := suite.readyz()
+	assert.Equal(suite.T(), http.StatusServiceUnavailable, code)
+	assert.Equal(suite.T(), "unavailable", response.Dependencies["database"].Status)
+	assert.NotEmpty(suite.T(), response.Dependencies["database"].Error)
+
+	// liveness does not depend on the database
+	req, _ := http.NewRequest("GET", "/healthz", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+}
+
+func (suite *HealthTestSuite) TestTimeout() {
+	handler := health.NewHandler(health.Check{Name: "slow", Run: func(ctx context.Context) (map[string]interface{}, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			return nil, errors.New("should have timed out")
+		}
+	}})
+	handler.Timeout = 20 * time.Millisecond
+	suite.router.GET("/slow", handler.Readyz)
+
+	req, _ := http.NewRequest("GET", "/slow", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	var response health.ReadinessResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(suite.T(), http.StatusServiceUnavailable, w.Code)
+	assert.Equal(suite.T(), context.DeadlineExceeded.Error(), response.Dependencies["slow"].Error)
+}
+
+func TestHealthSuite(t *testing.T) {
+	suite.Run(t, new(HealthTestSuite))
+}
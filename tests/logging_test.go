@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"polcompass/backend/logging"
+	"polcompass/backend/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func loggingRouter(logger *slog.Logger) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	controller := models.NewPolCompassController(models.NewMemoryStore())
+	router := gin.New()
+	router.Use(logging.RequestIDMiddleware(), logging.AccessLog(logger), models.Recovery())
+	router.GET("/polcompass/:id", controller.GET)
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
+	return router
+}
+
+func TestRequestID_Propagated(t *testing.T) {
+	var buf bytes.Buffer
+	router := loggingRouter(logging.New(&buf, "info"))
+
+	req, _ := http.NewRequest("GET", "/polcompass/999", nil)
+	req.Header.Set(logging.RequestIDHeader, "client-id.1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "client-id.1", w.Header().Get(logging.RequestIDHeader))
+
+	var apiErr models.APIError
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+	assert.Equal(t, "client-id.1", apiErr.RequestID)
+
+	lines := logLines(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "request", lines[0]["msg"])
+	assert.Equal(t, "WARN", lines[0]["level"])
+	assert.Equal(t, "client-id.1", lines[0]["request_id"])
+	assert.Equal(t, "/polcompass/:id", lines[0]["route"])
+	assert.Equal(t, float64(http.StatusNotFound), lines[0]["status"])
+}
+
+func TestRequestID_Generated(t *testing.T) {
+	router := loggingRouter(logging.New(&bytes.Buffer{}, "info"))
+
+	for _, header := range []string{"", "has spaces", strings.Repeat("a", 129), "line\nbreak"} {
+		req, _ := http.NewRequest("GET", "/polcompass/999", nil)
+		if header != "" {
+			req.Header[logging.RequestIDHeader] = []string{header}
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		requestID := w.Header().Get(logging.RequestIDHeader)
+		assert.Len(t, requestID, 32, "header %q", header)
+		assert.NotEqual(t, header, requestID)
+	}
+}
+
+func TestRecovery_LogsThePanic(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(&buf, "info")
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	defer slog.SetDefault(previous)
+
+	router := loggingRouter(logger)
+	req, _ := http.NewRequest("GET", "/panic", nil)
+	req.Header.Set(logging.RequestIDHeader, "panicking")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"request_id":"panicking"`)
+
+	lines := logLines(t, &buf)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "boom", lines[0]["panic"])
+	assert.Equal(t, "panicking", lines[0]["request_id"])
+	assert.Equal(t, "ERROR", lines[1]["level"])
+}
+
+func TestGormLogger_SlowQueries(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.NewGormLogger(logging.New(&buf, "info"), time.Nanosecond)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger})
+	require.NoError(t, err)
+	defer func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	}()
+
+	ctx := logging.WithRequestID(context.Background(), "slow-request")
+	require.NoError(t, db.WithContext(ctx).Exec("SELECT 1").Error)
+	assert.Error(t, db.WithContext(ctx).Exec("SELECT * FROM missing").Error)
+
+	lines := logLines(t, &buf)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "slow query", lines[0]["msg"])
+	assert.Equal(t, "WARN", lines[0]["level"])
+	assert.Equal(t, "SELECT 1", lines[0]["sql"])
+	assert.Equal(t, "slow-request", lines[0]["request_id"])
+	assert.Equal(t, "query failed", lines[1]["msg"])
+	assert.Contains(t, lines[1]["error"], "no such table")
+
+	buf.Reset()
+	quiet := logging.NewGormLogger(logging.New(&buf, "info"), time.Hour)
+	require.NoError(t, db.Session(&gorm.Session{Logger: quiet}).Exec("SELECT 1").Error)
+	assert.Empty(t, buf.String())
+}
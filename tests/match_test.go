@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"polcompass/backend/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type MatchTestSuite struct {
+	suite.Suite
+	database   testDatabase
+	DB         *gorm.DB
+	router     *gin.Engine
+	polcompass Polcompass
+}
+
+func (suite *MatchTestSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+}
+
+func (suite *MatchTestSuite) SetupTest() {
+	suite.DB = openTestDB(suite.T(), suite.database)
+
+	controller := models.NewPolCompassController(models.NewGormStore(suite.DB))
+	suite.router = gin.New()
+	suite.router.POST("/polcompass", controller.POST)
+	suite.router.PUT("/polcompass/:id", controller.PUT)
+	suite.router.POST("/polcompass/:id/references", controller.CreateReference)
+	suite.router.POST("/polcompass/:id/match", controller.Match)
+
+	w := serveJSON(suite.router, "POST", "/polcompass", PolCompassReq{
+		Field1Name: "Economic",
+		Field2Name: "Social",
+		Name:       "Match Compass",
+		Questions: []Question{
+			{Question: "Economic Question", Affects: "Economic", Direction: 1},
+			{Question: "Social Question", Affects: "Social", Direction: 1},
+			{Question: "Other Question", Affects: "Social", Direction: -1},
+		},
+	})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &suite.polcompass))
+
+	q := suite.polcompass.Questions
+	for _, reference := range []ReferencePointReq{
+		{Name: "Left Party", Answers: []AnswerReq{{QuestionID: q[0].ID, Value: -2}, {QuestionID: q[1].ID, Value: -2}, {QuestionID: q[2].ID, Value: 2}}},
+		{Name: "Right Party", Answers: []AnswerReq{{QuestionID: q[0].ID, Value: 2}, {QuestionID: q[1].ID, Value: 2}}},
+		{Name: "Placed Party", Scores: []AxisScore{{Name: "Economic", Score: 1}}},
+	} {
+		w := serveJSON(suite.router, "POST", fmt.Sprintf("/polcompass/%d/references", suite.polcompass.ID), reference)
+		suite.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	}
+}
+
+func (suite *MatchTestSuite) match(answers []AnswerReq) *httptest.ResponseRecorder {
+	return serveJSON(suite.router, "POST", fmt.Sprintf("/polcompass/%d/match", suite.polcompass.ID), ScoreReq{Answers: answers})
+}
+
+func (suite *MatchTestSuite) TestMatch() {
+	q := suite.polcompass.Questions
+	w := suite.match([]AnswerReq{{QuestionID: q[0].ID, Value: 2}, {QuestionID: q[1].ID, Value: 1}, {QuestionID: q[2].ID, Value: 0}})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	var match MatchResponse
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &match))
+	// the party placed by its scores has no answers to compare
+	suite.Require().Len(match.Parties, 2)
+
+	right := match.Parties[0]
+	assert.Equal(suite.T(), "Right Party", right.Name)
+	suite.Require().NotNil(right.Agreement)
+	assert.InDelta(suite.T(), 87.5, *right.Agreement, 1e-9)
+	suite.Require().Len(right.Questions, 2)
+	assert.Equal(suite.T(), QuestionMatch{QuestionID: q[1].ID, Question: "Social Question", Value: 1, PartyValue: 2, Agreement: 0.75}, right.Questions[1])
+
+	left := match.Parties[1]
+	assert.Equal(suite.T(), "Left Party", left.Name)
+	suite.Require().NotNil(left.Agreement)
+	assert.InDelta(suite.T(), 100*(0+0.25+0.5)/3, *left.Agreement, 1e-9)
+	assert.Len(suite.T(), left.Questions, 3)
+}
+
+func (suite *MatchTestSuite) TestMatch_Important() {
+	q := suite.polcompass.Questions
+	w := suite.match([]AnswerReq{{QuestionID: q[0].ID, Value: -2, Important: true}, {QuestionID: q[1].ID, Value: 2}})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	var match MatchResponse
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &match))
+	suite.Require().Len(match.Parties, 2)
+	assert.Equal(suite.T(), "Left Party", match.Parties[0].Name)
+	assert.InDelta(suite.T(), 100*2.0/3, *match.Parties[0].Agreement, 1e-9)
+	assert.True(suite.T(), match.Parties[0].Questions[0].Important)
+	assert.InDelta(suite.T(), 100*1.0/3, *match.Parties[1].Agreement, 1e-9)
+}
+
+func (suite *MatchTestSuite) TestMatch_NothingInCommon() {
+	q := suite.polcompass.Questions
+	w := suite.match([]AnswerReq{{QuestionID: q[2].ID, Value: 2}})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	var match MatchResponse
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &match))
+	suite.Require().Len(match.Parties, 2)
+	assert.Equal(suite.T(), "Left Party", match.Parties[0].Name)
+	assert.Equal(suite.T(), 100.0, *match.Parties[0].Agreement)
+	assert.Equal(suite.T(), "Right Party", match.Parties[1].Name)
+	assert.Nil(suite.T(), match.Parties[1].Agreement)
+	assert.Empty(suite.T(), match.Parties[1].Questions)
+}
+
+func (suite *MatchTestSuite) TestMatch_AfterEditingTheCompass() {
+	q := suite.polcompass.Questions
+	w := serveJSON(suite.router, "PUT", fmt.Sprintf("/polcompass/%d", suite.polcompass.ID), PolCompassReq{
+		Field1Name: "Economic",
+		Field2Name: "Social",
+		Name:       "Renamed Match Compass",
+		Questions: []Question{
+			{Question: "Economic Question", Affects: "Economic", Direction: 1},
+			{Question: "Social Question", Affects: "Social", Direction: 1},
+			{Question: "New Question", Affects: "Social", Direction: 1},
+		},
+	})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	w = suite.match([]AnswerReq{{QuestionID: q[0].ID, Value: 2}, {QuestionID: q[1].ID, Value: 2}})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	var match MatchResponse
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &match))
+	suite.Require().Len(match.Parties, 2)
+	assert.Equal(suite.T(), "Right Party", match.Parties[0].Name)
+	assert.Equal(suite.T(), 100.0, *match.Parties[0].Agreement)
+	// the answer of the left party to the removed question went with it
+	assert.Len(suite.T(), match.Parties[1].Questions, 2)
+	var count int64
+	suite.DB.Model(&ReferenceAnswer{}).Where("question_id = ?", q[2].ID).Count(&count)
+	assert.Zero(suite.T(), count)
+}
+
+func (suite *MatchTestSuite) TestMatch_InvalidAnswers() {
+	w := suite.match([]AnswerReq{{QuestionID: 999, Value: 1}})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	w = suite.match([]AnswerReq{{QuestionID: suite.polcompass.Questions[0].ID, Value: 3}})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func TestMatchTestSuite(t *testing.T) {
+	for _, database := range testDatabases() {
+		t.Run(database.name, func(t *testing.T) {
+			suite.Run(t, &MatchTestSuite{database: database})
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"polcompass/backend/metrics"
+	"polcompass/backend/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type MetricsTestSuite struct {
+	suite.Suite
+	DB     *gorm.DB
+	router *gin.Engine
+}
+
+func (suite *MetricsTestSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+}
+
+func (suite *MetricsTestSuite) SetupTest() {
+	var err error
+	suite.DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	err = suite.DB.AutoMigrate(&Polcompass{}, &Axis{}, &Question{}, &ReferencePoint{}, &ReferenceAnswer{})
+	suite.Require().NoError(err)
+
+	m := metrics.New()
+	sqlDB, _ := suite.DB.DB()
+	m.RegisterDB(sqlDB)
+
+	controller := models.NewPolCompassController(m.InstrumentStore(models.NewGormStore(suite.DB)))
+	suite.router = gin.New()
+	suite.router.Use(m.Middleware())
+	suite.router.GET("/metrics", m.Handler())
+	suite.router.POST("/polcompass", controller.POST)
+	suite.router.GET("/polcompass/:id", controller.GET)
+}
+
+func (suite *MetricsTestSuite) TearDownTest() {
+	sqlDB, _ := suite.DB.DB()
+	sqlDB.Close()
+}
+
+func (suite *MetricsTestSuite) request(method string, url string, body interface{}) int {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, url, &buf)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func (suite *MetricsTestSuite) scrape() string {
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	suite.Require().Equal(http.StatusOK, w.Code)
+	body, _ := io.ReadAll(w.Body)
+	return string(body)
+}
+
+func (suite *MetricsTestSuite) TestRequestsAndDomainCounters() {
+	requestBody := PolCompassReq{
+		Field1Name: "Economic",
+		Field2Name: "Social",
+		Name:       "Metrics Compass",
+		Questions: []Question{
+			{Question: "Question 1", Affects: "Economic", Direction: 1},
+			{Question: "Question 2", Affects: "Social", Direction: 1},
+		},
+	}
+	suite.Require().Equal(http.StatusOK, suite.request("POST", "/polcompass", requestBody))
+	suite.Require().Equal(http.StatusOK, suite.request("GET", "/polcompass/1", nil))
+	suite.Require().Equal(http.StatusNotFound, suite.request("GET", "/polcompass/2", nil))
+	suite.Require().Equal(http.StatusNotFound, suite.request("GET", "/does/not/exist", nil))
+	suite.Require().Equal(http.StatusBadRequest, suite.request("POST", "/polcompass", PolCompassReq{}))
+
+	body := suite.scrape()
+	assert.Contains(suite.T(), body, `http_requests_total{code="200",method="POST",route="/polcompass"} 1`)
+	assert.Contains(suite.T(), body, `http_requests_total{code="400",method="POST",route="/polcompass"} 1`)
+	assert.Contains(suite.T(), body, `http_requests_total{code="200",method="GET",route="/polcompass/:id"} 1`)
+	assert.Contains(suite.T(), body, `http_requests_total{code="404",method="GET",route="/polcompass/:id"} 1`)
+	assert.Contains(suite.T(), body, `http_requests_total{code="404",method="GET",route="unmatched"} 1`)
+	assert.Contains(suite.T(), body, `http_request_duration_seconds_count{method="GET",route="/polcompass/:id"} 2`)
+	assert.Contains(suite.T(), body, "polcompass_compasses_created_total 1")
+	assert.Contains(suite.T(), body, "polcompass_questions_upserted_total 2")
+	assert.Contains(suite.T(), body, "polcompass_responses_saved_total 0")
+	assert.Contains(suite.T(), body, "go_sql_max_open_connections")
+}
+
+func TestMetricsSuite(t *testing.T) {
+	suite.Run(t, new(MetricsTestSuite))
+}
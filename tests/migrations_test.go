@@ -0,0 +1,246 @@
+package tests
+
+import (
+	"errors"
+	"polcompass/backend/migrations"
+	"polcompass/backend/models"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type MigrationsTestSuite struct {
+	suite.Suite
+	DB *gorm.DB
+}
+
+func (suite *MigrationsTestSuite) SetupTest() {
+	var err error
+	suite.DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	suite.Require().NoError(err)
+}
+
+func (suite *MigrationsTestSuite) TearDownTest() {
+	sqlDB, _ := suite.DB.DB()
+	sqlDB.Close()
+}
+
+func (suite *MigrationsTestSuite) version() uint {
+	version, err := migrations.New(suite.DB).Version()
+	suite.Require().NoError(err)
+	return version
+}
+
+func (suite *MigrationsTestSuite) TestUp_FromScratch() {
+	migrator := migrations.New(suite.DB)
+	assert.Equal(suite.T(), uint(0), suite.version())
+
+	applied, err := migrator.Up()
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), applied, len(migrations.All))
+	assert.Equal(suite.T(), migrator.Latest(), suite.version())
+
+	for _, table := range []string{"polcompasses", "questions", "axes", "responses", "answers", "schema_migrations"} {
+		assert.True(suite.T(), suite.DB.Migrator().HasTable(table), table)
+	}
+	assert.True(suite.T(), suite.DB.Migrator().HasColumn(&Question{}, "Effects"))
+
+	applied, err = migrator.Up()
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), applied)
+
+	statuses, err := migrator.Status()
+	suite.Require().NoError(err)
+	for _, status := range statuses {
+		assert.True(suite.T(), status.Applied, status.Name)
+	}
+}
+
+func (suite *MigrationsTestSuite) TestUp_DatabaseCreatedByAutoMigrate() {
+	// Databases created before migrations have the first tables but no schema_migrations
+	_, err := (&migrations.Migrator{DB: suite.DB, Migrations: migrations.All[:1]}).Up()
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.DB.Migrator().DropTable("schema_migrations"))
+
+	old := Polcompass{Field1Name: "Economic", Field2Name: "Social", Field1QuestionQty: 3, Field2QuestionQty: 4, Name: "Old Compass"}
+	suite.Require().NoError(suite.DB.Omit("Axes", "Questions", "Scale", "Regions").Create(&old).Error)
+	deleted := Polcompass{Field1Name: "Left", Field2Name: "Right", Name: "Deleted Compass"}
+	suite.Require().NoError(suite.DB.Omit("Axes", "Questions", "Scale", "Regions").Create(&deleted).Error)
+	suite.Require().NoError(suite.DB.Exec("UPDATE polcompasses SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?", deleted.ID).Error)
+	unnamed := Polcompass{Name: "Unnamed Compass"}
+	suite.Require().NoError(suite.DB.Omit("Axes", "Questions", "Scale", "Regions").Create(&unnamed).Error)
+
+	_, err = migrations.New(suite.DB).Up()
+	suite.Require().NoError(err)
+
+	var axes []Axis
+	suite.DB.Where("polcompass_id = ?", old.ID).Order("position").Find(&axes)
+	suite.Require().Len(axes, 2)
+	assert.Equal(suite.T(), "Economic", axes[0].Name)
+	assert.Equal(suite.T(), 3, axes[0].QuestionQty)
+	assert.Equal(suite.T(), "Social", axes[1].Name)
+	assert.Equal(suite.T(), 4, axes[1].QuestionQty)
+
+	var count int64
+	suite.DB.Model(&Axis{}).Where("polcompass_id = ?", deleted.ID).Count(&count)
+	assert.Equal(suite.T(), int64(2), count)
+	suite.DB.Model(&Axis{}).Where("polcompass_id = ?", unnamed.ID).Count(&count)
+	assert.Equal(suite.T(), int64(0), count)
+}
+
+func (suite *MigrationsTestSuite) TestUp_MovesReferenceAnswers() {
+	_, err := (&migrations.Migrator{DB: suite.DB, Migrations: migrations.All[:7]}).Up()
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(suite.DB.Exec("INSERT INTO polcompasses (id, name) VALUES (1, 'Compass')").Error)
+	suite.Require().NoError(suite.DB.Exec("INSERT INTO questions (id, question, polcompass_id) VALUES (1, 'Question 1', 1), (2, 'Question 2', 1)").Error)
+	suite.Require().NoError(suite.DB.Exec(`INSERT INTO reference_points (id, polcompass_id, name, answers) VALUES (1, 1, 'Party', '[{"question_id":1,"value":2},{"question_id":2,"skipped":true},{"question_id":9,"value":1}]')`).Error)
+
+	_, err = migrations.New(suite.DB).Up()
+	suite.Require().NoError(err)
+	assert.False(suite.T(), suite.DB.Migrator().HasColumn(&ReferencePoint{}, "answers"))
+
+	var answers []ReferenceAnswer
+	suite.DB.Order("id").Find(&answers)
+	suite.Require().Len(answers, 2)
+	assert.Equal(suite.T(), uint(1), answers[0].QuestionID)
+	assert.Equal(suite.T(), 2, answers[0].Value)
+	assert.True(suite.T(), answers[1].Skipped)
+
+	_, err = migrations.New(suite.DB).Down(1)
+	suite.Require().NoError(err)
+	var stored string
+	suite.DB.Raw("SELECT answers FROM reference_points WHERE id = 1").Scan(&stored)
+	assert.JSONEq(suite.T(), `[{"question_id":1,"value":2,"skipped":false},{"question_id":2,"value":0,"skipped":true}]`, stored)
+}
+
+func (suite *MigrationsTestSuite) TestDown() {
+	migrator := migrations.New(suite.DB)
+	_, err := migrator.Up()
+	suite.Require().NoError(err)
+
+	// revert everything after the responses
+	steps := len(migrations.All) - 2
+	reverted, err := migrator.Down(steps)
+	suite.Require().NoError(err)
+	suite.Require().Len(reverted, steps)
+	assert.Equal(suite.T(), migrations.All[len(migrations.All)-1].Name, reverted[0].Name)
+	assert.Equal(suite.T(), "create_axes", reverted[steps-1].Name)
+	assert.Equal(suite.T(), uint(2), suite.version())
+	assert.False(suite.T(), suite.DB.Migrator().HasColumn(&Answer{}, "Skipped"))
+	assert.False(suite.T(), suite.DB.Migrator().HasTable("axes"))
+	assert.False(suite.T(), suite.DB.Migrator().HasColumn(&Question{}, "Effects"))
+
+	reverted, err = migrator.Down(10)
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), reverted, 2)
+	assert.Equal(suite.T(), uint(0), suite.version())
+	assert.False(suite.T(), suite.DB.Migrator().HasTable("polcompasses"))
+
+	_, err = migrator.Up()
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), migrator.Latest(), suite.version())
+}
+
+func (suite *MigrationsTestSuite) TestUp_FailureRollsBack() {
+	migrator := &migrations.Migrator{DB: suite.DB, Migrations: []migrations.Migration{
+		{Version: 1, Name: "create_first", Up: func(tx *gorm.DB) error {
+			return tx.Exec("CREATE TABLE first (id INTEGER)").Error
+		}},
+		{Version: 2, Name: "broken", Up: func(tx *gorm.DB) error {
+			if err := tx.Exec("CREATE TABLE second (id INTEGER)").Error; err != nil {
+				return err
+			}
+			return errors.New("broken migration")
+		}},
+	}}
+
+	applied, err := migrator.Up()
+	suite.Require().Error(err)
+	assert.Contains(suite.T(), err.Error(), "broken")
+	assert.Len(suite.T(), applied, 1)
+	assert.Equal(suite.T(), uint(1), suite.version())
+	assert.True(suite.T(), suite.DB.Migrator().HasTable("first"))
+	assert.False(suite.T(), suite.DB.Migrator().HasTable("second"))
+
+	_, err = migrator.Down(1)
+	assert.Error(suite.T(), err, "migrations without a Down step cannot be reverted")
+}
+
+func (suite *MigrationsTestSuite) TestDuplicateVersions() {
+	up := func(tx *gorm.DB) error { return nil }
+	migrator := &migrations.Migrator{DB: suite.DB, Migrations: []migrations.Migration{
+		{Version: 1, Name: "first", Up: up},
+		{Version: 1, Name: "second", Up: up},
+	}}
+
+	_, err := migrator.Up()
+	assert.Error(suite.T(), err)
+}
+
+// The migrated schema has the columns and indexes the models expect.
+func (suite *MigrationsTestSuite) TestUp_MatchesModels() {
+	_, err := migrations.New(suite.DB).Up()
+	suite.Require().NoError(err)
+
+	expected, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	suite.Require().NoError(err)
+	defer func() {
+		sqlDB, _ := expected.DB()
+		sqlDB.Close()
+	}()
+	tables := []interface{}{&Polcompass{}, &Axis{}, &Question{}, &Response{}, &Answer{}, &ReferencePoint{}, &ReferenceAnswer{}}
+	suite.Require().NoError(expected.AutoMigrate(tables...))
+
+	for _, model := range tables {
+		assert.Equal(suite.T(), suite.columns(expected, model), suite.columns(suite.DB, model))
+		assert.Equal(suite.T(), suite.indexes(expected, model), suite.indexes(suite.DB, model))
+	}
+}
+
+func (suite *MigrationsTestSuite) columns(db *gorm.DB, model interface{}) []string {
+	columnTypes, err := db.Migrator().ColumnTypes(model)
+	suite.Require().NoError(err)
+	var columns []string
+	for _, column := range columnTypes {
+		columns = append(columns, column.Name()+" "+column.DatabaseTypeName())
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+func (suite *MigrationsTestSuite) indexes(db *gorm.DB, model interface{}) []string {
+	indexes, err := db.Migrator().GetIndexes(model)
+	suite.Require().NoError(err)
+	var names []string
+	for _, index := range indexes {
+		names = append(names, index.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestMigrationsSuite(t *testing.T) {
+	suite.Run(t, new(MigrationsTestSuite))
+}
+
+// The handlers run on the migrated schema, not only on the one AutoMigrate creates.
+func TestMigratedStoreSuite(t *testing.T) {
+	suite.Run(t, &StoreTestSuite{newStore: func() (CompassStore, func()) {
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := migrations.New(db).Up(); err != nil {
+			t.Fatal(err)
+		}
+		return models.NewGormStore(db), func() {
+			sqlDB, _ := db.DB()
+			sqlDB.Close()
+		}
+	}})
+}
@@ -0,0 +1,34 @@
+package tests
+
+import "polcompass/backend/models"
+
+type (
+	PolCompassController = models.PolCompassController
+	PolCompassReq        = models.PolCompassReq
+	Polcompass           = models.Polcompass
+	Axis                 = models.Axis
+	Question             = models.Question
+	AnswerReq            = models.AnswerReq
+	ScoreReq             = models.ScoreReq
+	ScoreResponse        = models.ScoreResponse
+	Response             = models.Response
+	Answer               = models.Answer
+	AxisScore            = models.AxisScore
+	Effect               = models.Effect
+	FieldError           = models.FieldError
+	SummaryResponse      = models.SummaryResponse
+	Summary              = models.Summary
+	CompassStore         = models.CompassStore
+	Scale                = models.Scale
+	ScaleOption          = models.ScaleOption
+	Region               = models.Region
+	ReferencePoint       = models.ReferencePoint
+	ReferencePointReq    = models.ReferencePointReq
+	ReferenceAnswer      = models.ReferenceAnswer
+	MatchResponse        = models.MatchResponse
+	QuestionMatch        = models.QuestionMatch
+	QuestionContribution = models.QuestionContribution
+	SkippedQuestion      = models.SkippedQuestion
+	Rectangle            = models.Rectangle
+	Point                = models.Point
+)
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"polcompass/backend/models"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -16,9 +17,9 @@ func BenchmarkPolCompassPOST(b *testing.B) {
 	gin.SetMode(gin.TestMode)
 
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	db.AutoMigrate(&Polcompass{}, &Question{})
+	db.AutoMigrate(&Polcompass{}, &Axis{}, &Question{}, &ReferencePoint{}, &ReferenceAnswer{})
 
-	controller := &PolCompassController{DB: db}
+	controller := models.NewPolCompassController(models.NewGormStore(db))
 	router := gin.New()
 	router.POST("/polcompass", controller.POST)
 
@@ -50,7 +51,7 @@ func BenchmarkPolCompassGET(b *testing.B) {
 	gin.SetMode(gin.TestMode)
 
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	db.AutoMigrate(&Polcompass{}, &Question{})
+	db.AutoMigrate(&Polcompass{}, &Axis{}, &Question{}, &ReferencePoint{}, &ReferenceAnswer{})
 
 	// Setup test data
 	polcompass := Polcompass{
@@ -69,7 +70,7 @@ func BenchmarkPolCompassGET(b *testing.B) {
 	}
 	db.Create(&questions)
 
-	controller := &PolCompassController{DB: db}
+	controller := models.NewPolCompassController(models.NewGormStore(db))
 	router := gin.New()
 	router.GET("/polcompass", controller.GET)
 
@@ -85,7 +86,7 @@ func BenchmarkPolCompassFirst(b *testing.B) {
 	gin.SetMode(gin.TestMode)
 
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	db.AutoMigrate(&Polcompass{}, &Question{})
+	db.AutoMigrate(&Polcompass{}, &Axis{}, &Question{}, &ReferencePoint{}, &ReferenceAnswer{})
 
 	// Setup test data
 	polcompass := Polcompass{
@@ -103,7 +104,7 @@ func BenchmarkPolCompassFirst(b *testing.B) {
 	}
 	db.Create(&questions)
 
-	controller := &PolCompassController{DB: db}
+	controller := models.NewPolCompassController(models.NewGormStore(db))
 	router := gin.New()
 	router.GET("/polcompass/first", controller.First)
 
@@ -119,9 +120,9 @@ func BenchmarkPolCompassPOSTLargeDataset(b *testing.B) {
 	gin.SetMode(gin.TestMode)
 
 	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	db.AutoMigrate(&Polcompass{}, &Question{})
+	db.AutoMigrate(&Polcompass{}, &Axis{}, &Question{}, &ReferencePoint{}, &ReferenceAnswer{})
 
-	controller := &PolCompassController{DB: db}
+	controller := models.NewPolCompassController(models.NewGormStore(db))
 	router := gin.New()
 	router.POST("/polcompass", controller.POST)
 
@@ -135,7 +136,7 @@ func BenchmarkPolCompassPOSTLargeDataset(b *testing.B) {
 		questions = append(questions, Question{
 			Question:  "Benchmark Question " + string(rune(i)),
 			Affects:   field,
-			Direction: i - 50,
+			Direction: 1 - 2*(i%3%2),
 		})
 	}
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"polcompass/backend/models"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -30,14 +31,15 @@ func (suite *PolCompassIntegrationSuite) SetupSuite() {
 	suite.DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	suite.Require().NoError(err)
 
-	err = suite.DB.AutoMigrate(&Polcompass{}, &Question{})
+	err = suite.DB.AutoMigrate(&Polcompass{}, &Axis{}, &Question{}, &ReferencePoint{}, &ReferenceAnswer{})
 	suite.Require().NoError(err)
 
-	suite.controller = &PolCompassController{DB: suite.DB}
+	suite.controller = models.NewPolCompassController(models.NewGormStore(suite.DB))
 	suite.router = gin.New()
 
 	suite.router.GET("/polcompass", suite.controller.GET)
 	suite.router.GET("/polcompass/first", suite.controller.First)
+	suite.router.GET("/polcompass/:id", suite.controller.GET)
 	suite.router.POST("/polcompass", suite.controller.POST)
 
 	suite.server = httptest.NewServer(suite.router)
@@ -52,7 +54,10 @@ func (suite *PolCompassIntegrationSuite) TearDownSuite() {
 func (suite *PolCompassIntegrationSuite) SetupTest() {
 	// Clean database before each test
 	suite.DB.Exec("DELETE FROM questions")
+	suite.DB.Exec("DELETE FROM axes")
 	suite.DB.Exec("DELETE FROM polcompasses")
+	// Tests look compasses up by id, restart the autoincrement counters
+	suite.DB.Exec("DELETE FROM sqlite_sequence")
 }
 
 func (suite *PolCompassIntegrationSuite) TestCompleteWorkflow() {
@@ -77,9 +82,11 @@ func (suite *PolCompassIntegrationSuite) TestCompleteWorkflow() {
 
 	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
 
-	var createResponse map[string]interface{}
+	var createResponse Polcompass
 	json.NewDecoder(resp.Body).Decode(&createResponse)
-	assert.Equal(suite.T(), "Added sucessfully to the database", createResponse["message"])
+	assert.NotZero(suite.T(), createResponse.ID)
+	assert.Equal(suite.T(), "Integration Test Compass", createResponse.Name)
+	assert.Len(suite.T(), createResponse.Questions, 4)
 
 	// Step 2: Retrieve the first polcompass
 	resp, err = http.Get(suite.server.URL + "/polcompass/first")
@@ -212,10 +219,14 @@ func (suite *PolCompassIntegrationSuite) TestErrorHandlingWorkflow() {
 	suite.Require().NoError(err)
 	defer resp.Body.Close()
 
-	assert.Equal(suite.T(), http.StatusOK, resp.StatusCode)
-	var response Polcompass
-	json.NewDecoder(resp.Body).Decode(&response)
-	assert.Equal(suite.T(), uint(0), response.ID)
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
+
+	// Same lookup through the path parameter
+	resp, err = http.Get(suite.server.URL + "/polcompass/999")
+	suite.Require().NoError(err)
+	defer resp.Body.Close()
+
+	assert.Equal(suite.T(), http.StatusNotFound, resp.StatusCode)
 
 	// Test getting polcompass without ID
 	resp, err = http.Get(suite.server.URL + "/polcompass")
@@ -300,7 +311,7 @@ func (suite *PolCompassIntegrationSuite) TestLargeDatasetIntegration() {
 		questions = append(questions, Question{
 			Question:  fmt.Sprintf("Large Dataset Question %d", i),
 			Affects:   field,
-			Direction: (i % 11) - 5, // Range from -5 to 5
+			Direction: 1 - 2*(i%3%2), // Alternates between 1 and -1 independently of the field
 		})
 	}
 
@@ -6,17 +6,18 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"polcompass/backend/models"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 type PolCompassTestSuite struct {
 	suite.Suite
+	database   testDatabase
 	DB         *gorm.DB
 	controller *PolCompassController
 	router     *gin.Engine
@@ -27,26 +28,17 @@ func (suite *PolCompassTestSuite) SetupSuite() {
 }
 
 func (suite *PolCompassTestSuite) SetupTest() {
-	var err error
-	suite.DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	suite.Require().NoError(err)
+	suite.DB = openTestDB(suite.T(), suite.database)
 
-	err = suite.DB.AutoMigrate(&Polcompass{}, &Question{})
-	suite.Require().NoError(err)
-
-	suite.controller = &PolCompassController{DB: suite.DB}
+	suite.controller = models.NewPolCompassController(models.NewGormStore(suite.DB))
 	suite.router = gin.New()
 
 	suite.router.GET("/polcompass", suite.controller.GET)
 	suite.router.GET("/polcompass/first", suite.controller.First)
+	suite.router.GET("/polcompass/:id", suite.controller.GET)
 	suite.router.POST("/polcompass", suite.controller.POST)
 }
 
-func (suite *PolCompassTestSuite) TearDownTest() {
-	sqlDB, _ := suite.DB.DB()
-	sqlDB.Close()
-}
-
 // Test GET method
 func (suite *PolCompassTestSuite) TestGET_MissingID() {
 	req, _ := http.NewRequest("GET", "/polcompass", nil)
@@ -57,7 +49,10 @@ func (suite *PolCompassTestSuite) TestGET_MissingID() {
 
 	var response map[string]interface{}
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Equal(suite.T(), "You need to specify an id", response["message"])
+	assert.Equal(suite.T(), "You need to specify an id", response["detail"])
+	assert.Equal(suite.T(), "missing_parameter", response["code"])
+	assert.Equal(suite.T(), float64(http.StatusBadRequest), response["status"])
+	assert.Equal(suite.T(), "application/problem+json", w.Header().Get("Content-Type"))
 }
 
 func (suite *PolCompassTestSuite) TestGET_InvalidID() {
@@ -69,7 +64,8 @@ func (suite *PolCompassTestSuite) TestGET_InvalidID() {
 
 	var response map[string]interface{}
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Equal(suite.T(), "You need to specify an id", response["message"])
+	assert.Equal(suite.T(), "id must be a positive integer", response["detail"])
+	assert.Equal(suite.T(), "invalid_id", response["code"])
 }
 
 func (suite *PolCompassTestSuite) TestGET_ValidID() {
@@ -114,7 +110,8 @@ func (suite *PolCompassTestSuite) TestFirst_NoData() {
 
 	var response map[string]interface{}
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Equal(suite.T(), "PolCompass not found", response["message"])
+	assert.Equal(suite.T(), "PolCompass not found", response["detail"])
+	assert.Equal(suite.T(), "compass_not_found", response["code"])
 }
 
 func (suite *PolCompassTestSuite) TestFirst_WithData() {
@@ -158,7 +155,8 @@ func (suite *PolCompassTestSuite) TestPOST_InvalidJSON() {
 
 	var response map[string]interface{}
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Contains(suite.T(), response["message"], "Bad request for polcompass request")
+	assert.Contains(suite.T(), response["detail"], "Bad request for polcompass request")
+	assert.Equal(suite.T(), "invalid_body", response["code"])
 }
 
 func (suite *PolCompassTestSuite) TestPOST_UnknownField() {
@@ -180,9 +178,15 @@ func (suite *PolCompassTestSuite) TestPOST_UnknownField() {
 
 	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
 
-	var response map[string]interface{}
+	var response struct {
+		Code   string       `json:"code"`
+		Errors []FieldError `json:"details"`
+	}
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Contains(suite.T(), response["message"], "An unknown field was added in the questions : Unknown")
+	assert.Equal(suite.T(), "validation_failed", response.Code)
+	suite.Require().Len(response.Errors, 1)
+	assert.Equal(suite.T(), "questions[0].affects", response.Errors[0].Field)
+	assert.Equal(suite.T(), "must be one of the axes Economic, Social", response.Errors[0].Message)
 }
 
 func (suite *PolCompassTestSuite) TestPOST_MissingFieldNames() {
@@ -204,9 +208,13 @@ func (suite *PolCompassTestSuite) TestPOST_MissingFieldNames() {
 
 	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
 
-	var response map[string]interface{}
+	var response struct {
+		Errors []FieldError `json:"details"`
+	}
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Contains(suite.T(), response["message"], "An unknown field was added in the questions")
+	suite.Require().Len(response.Errors, 1)
+	assert.Equal(suite.T(), "field1_name", response.Errors[0].Field)
+	assert.Equal(suite.T(), "required_without", response.Errors[0].Rule)
 }
 
 func (suite *PolCompassTestSuite) TestPOST_ValidRequest() {
@@ -230,13 +238,15 @@ func (suite *PolCompassTestSuite) TestPOST_ValidRequest() {
 
 	assert.Equal(suite.T(), http.StatusOK, w.Code)
 
-	var response map[string]interface{}
+	var response Polcompass
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Equal(suite.T(), "Added sucessfully to the database", response["message"])
+	assert.NotZero(suite.T(), response.ID)
+	assert.Equal(suite.T(), "Test Compass", response.Name)
+	assert.Len(suite.T(), response.Questions, 3)
 
 	// Verify data was saved correctly
 	var savedPolcompass Polcompass
-	suite.DB.Preload("Questions").First(&savedPolcompass)
+	suite.DB.Preload("Questions").First(&savedPolcompass, response.ID)
 	assert.Equal(suite.T(), "Test Compass", savedPolcompass.Name)
 	assert.Equal(suite.T(), "Economic", savedPolcompass.Field1Name)
 	assert.Equal(suite.T(), "Social", savedPolcompass.Field2Name)
@@ -256,6 +266,35 @@ func (suite *PolCompassTestSuite) TestPOST_ValidRequest() {
 	}
 }
 
+func (suite *PolCompassTestSuite) TestPOST_RollsBackOnQuestionError() {
+	// Dropping the questions table makes the question insert fail after the polcompass insert
+	suite.DB.Migrator().DropTable(&Question{})
+
+	requestBody := PolCompassReq{
+		Field1Name:  "Economic",
+		Field2Name:  "Social",
+		Name:        "Orphan Compass",
+		Description: "Should not be saved",
+		Questions: []Question{
+			{Question: "Economic Question", Affects: "Economic", Direction: 1},
+		},
+	}
+
+	jsonData, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("POST", "/polcompass", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusInternalServerError, w.Code)
+
+	var count int64
+	suite.DB.Model(&Polcompass{}).Count(&count)
+	assert.Equal(suite.T(), int64(0), count)
+	suite.DB.Model(&Axis{}).Count(&count)
+	assert.Equal(suite.T(), int64(0), count)
+}
+
 func (suite *PolCompassTestSuite) TestPOST_EmptyQuestions() {
 	requestBody := PolCompassReq{
 		Field1Name:  "Economic",
@@ -273,9 +312,10 @@ func (suite *PolCompassTestSuite) TestPOST_EmptyQuestions() {
 
 	assert.Equal(suite.T(), http.StatusOK, w.Code)
 
-	var response map[string]interface{}
+	var response Polcompass
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Equal(suite.T(), "Added sucessfully to the database", response["message"])
+	assert.NotZero(suite.T(), response.ID)
+	assert.Equal(suite.T(), "Empty Compass", response.Name)
 
 	// Verify data was saved correctly
 	var savedPolcompass Polcompass
@@ -331,7 +371,7 @@ func (suite *PolCompassTestSuite) TestPOST_DuplicateQuestions() {
 	assert.Len(suite.T(), polcompasses, 2)
 }
 
-func (suite *PolCompassTestSuite) TestPOST_NegativeDirections() {
+func (suite *PolCompassTestSuite) TestPOST_InvalidDirections() {
 	requestBody := PolCompassReq{
 		Field1Name:  "Left",
 		Field2Name:  "Right",
@@ -339,6 +379,7 @@ func (suite *PolCompassTestSuite) TestPOST_NegativeDirections() {
 		Description: "Testing negative and positive directions",
 		Questions: []Question{
 			{Question: "Negative Direction Question", Affects: "Left", Direction: -5},
+			{Question: "Valid Direction Question", Affects: "Right", Direction: -1},
 			{Question: "Zero Direction Question", Affects: "Right", Direction: 0},
 			{Question: "Positive Direction Question", Affects: "Left", Direction: 3},
 		},
@@ -350,22 +391,67 @@ func (suite *PolCompassTestSuite) TestPOST_NegativeDirections() {
 	w := httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
-	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
 
-	// Verify directions are preserved
-	var savedPolcompass Polcompass
-	suite.DB.Preload("Questions").First(&savedPolcompass)
+	var response struct {
+		Errors []FieldError `json:"details"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
 
-	for _, question := range savedPolcompass.Questions {
-		switch question.Question {
-		case "Negative Direction Question":
-			assert.Equal(suite.T(), -5, question.Direction)
-		case "Zero Direction Question":
-			assert.Equal(suite.T(), 0, question.Direction)
-		case "Positive Direction Question":
-			assert.Equal(suite.T(), 3, question.Direction)
-		}
+	var fields []string
+	for _, fieldError := range response.Errors {
+		fields = append(fields, fieldError.Field)
 	}
+	assert.Equal(suite.T(), []string{"questions[0].direction", "questions[2].direction", "questions[3].direction"}, fields)
+
+	// Nothing is saved when the request is invalid
+	var count int64
+	suite.DB.Model(&Polcompass{}).Count(&count)
+	assert.Equal(suite.T(), int64(0), count)
+}
+
+func (suite *PolCompassTestSuite) TestPOST_ValidationErrors() {
+	requestBody := PolCompassReq{
+		Field1Name:  "Same",
+		Field2Name:  "Same",
+		Name:        "",
+		Description: "Testing every rule",
+		Questions: []Question{
+			{Question: "", Affects: "Same", Direction: 1},
+			{Question: "Duplicate", Affects: "Same", Direction: 1},
+			{Question: "Duplicate", Affects: "Same", Direction: -1},
+			{Question: "No axis", Direction: 1},
+		},
+	}
+
+	jsonData, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("POST", "/polcompass", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	var response struct {
+		Code    string       `json:"code"`
+		Message string       `json:"detail"`
+		Errors  []FieldError `json:"details"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(suite.T(), "validation_failed", response.Code)
+	assert.Equal(suite.T(), "The request contains invalid fields", response.Message)
+
+	rules := make(map[string]string)
+	for _, fieldError := range response.Errors {
+		rules[fieldError.Field] = fieldError.Rule
+	}
+	assert.Equal(suite.T(), map[string]string{
+		"field2_name":           "nefield",
+		"name":                  "required",
+		"questions[0].question": "required",
+		"questions[2].question": "unique",
+		"questions[3].affects":  "required_without",
+	}, rules)
 }
 
 func (suite *PolCompassTestSuite) TestGET_NonExistentID() {
@@ -373,12 +459,66 @@ func (suite *PolCompassTestSuite) TestGET_NonExistentID() {
 	w := httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(suite.T(), "compass_not_found", response["code"])
+}
+
+func (suite *PolCompassTestSuite) TestGET_PathID() {
+	polcompass := Polcompass{Field1Name: "Economic", Field2Name: "Social", Name: "Path Compass", Description: "Path Description"}
+	suite.DB.Create(&polcompass)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/polcompass/%d", polcompass.ID), nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
 	assert.Equal(suite.T(), http.StatusOK, w.Code)
 
 	var response Polcompass
 	json.Unmarshal(w.Body.Bytes(), &response)
-	// Should return empty polcompass when ID doesn't exist
-	assert.Equal(suite.T(), uint(0), response.ID)
+	assert.Equal(suite.T(), "Path Compass", response.Name)
+
+	// The static route still wins over the id parameter
+	req, _ = http.NewRequest("GET", "/polcompass/first", nil)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("GET", "/polcompass/abc", nil)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func (suite *PolCompassTestSuite) TestGET_DeletedID() {
+	polcompass := Polcompass{Field1Name: "Economic", Field2Name: "Social", Name: "Deleted Compass", Description: "Deleted Description"}
+	suite.DB.Create(&polcompass)
+	suite.DB.Delete(&polcompass)
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/polcompass/%d", polcompass.ID), nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusGone, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(suite.T(), "compass_deleted", response["code"])
+}
+
+func (suite *PolCompassTestSuite) TestGET_DatabaseFailure() {
+	sqlDB, _ := suite.DB.DB()
+	sqlDB.Close()
+
+	req, _ := http.NewRequest("GET", "/polcompass/1", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusInternalServerError, w.Code)
 }
 
 func (suite *PolCompassTestSuite) TestPOST_LargeDataSet() {
@@ -391,7 +531,7 @@ func (suite *PolCompassTestSuite) TestPOST_LargeDataSet() {
 		questions = append(questions, Question{
 			Question:  fmt.Sprintf("Question %d", i),
 			Affects:   field,
-			Direction: i - 25, // Range from -25 to 24
+			Direction: 1 - 2*(i%3%2), // Alternates between 1 and -1 independently of the field
 		})
 	}
 
@@ -420,5 +560,9 @@ func (suite *PolCompassTestSuite) TestPOST_LargeDataSet() {
 }
 
 func TestPolCompassSuite(t *testing.T) {
-	suite.Run(t, new(PolCompassTestSuite))
+	for _, database := range testDatabases() {
+		t.Run(database.name, func(t *testing.T) {
+			suite.Run(t, &PolCompassTestSuite{database: database})
+		})
+	}
 }
@@ -0,0 +1,286 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"polcompass/backend/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type PolCompassUpdateTestSuite struct {
+	suite.Suite
+	database   testDatabase
+	DB         *gorm.DB
+	controller *PolCompassController
+	router     *gin.Engine
+	polcompass Polcompass
+}
+
+func (suite *PolCompassUpdateTestSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+}
+
+func (suite *PolCompassUpdateTestSuite) SetupTest() {
+	suite.DB = openTestDB(suite.T(), suite.database)
+
+	suite.controller = models.NewPolCompassController(models.NewGormStore(suite.DB))
+	suite.router = gin.New()
+
+	suite.router.GET("/polcompass", suite.controller.GET)
+	suite.router.PUT("/polcompass/:id", suite.controller.PUT)
+	suite.router.PATCH("/polcompass/:id", suite.controller.PATCH)
+	suite.router.DELETE("/polcompass/:id", suite.controller.DELETE)
+	suite.router.POST("/polcompass/:id/restore", suite.controller.Restore)
+
+	suite.polcompass = Polcompass{
+		Field1Name:        "Economic",
+		Field2Name:        "Social",
+		Field1QuestionQty: 1,
+		Field2QuestionQty: 1,
+		Name:              "Test Compass",
+		Description:       "Test Description",
+	}
+	suite.DB.Create(&suite.polcompass)
+
+	questions := []Question{
+		{Question: "Test Question 1", Affects: "Economic", Direction: 1, PolcompassID: suite.polcompass.ID},
+		{Question: "Test Question 2", Affects: "Social", Direction: -1, PolcompassID: suite.polcompass.ID},
+	}
+	suite.DB.Create(&questions)
+}
+
+func (suite *PolCompassUpdateTestSuite) TestPUT_ReplacesCompass() {
+	requestBody := PolCompassReq{
+		Field1Name:  "Left",
+		Field2Name:  "Right",
+		Name:        "Renamed Compass",
+		Description: "New Description",
+		Questions: []Question{
+			{Question: "Left Question 1", Affects: "Left", Direction: 1},
+			{Question: "Left Question 2", Affects: "Left", Direction: -1},
+			{Question: "Right Question 1", Affects: "Right", Direction: 1},
+		},
+	}
+
+	w := serveJSON(suite.router, "PUT", "/polcompass/1", requestBody)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var savedPolcompass Polcompass
+	suite.DB.Preload("Questions").First(&savedPolcompass, suite.polcompass.ID)
+	assert.Equal(suite.T(), "Renamed Compass", savedPolcompass.Name)
+	assert.Equal(suite.T(), "New Description", savedPolcompass.Description)
+	assert.Equal(suite.T(), "Left", savedPolcompass.Field1Name)
+	assert.Equal(suite.T(), "Right", savedPolcompass.Field2Name)
+	assert.Equal(suite.T(), 2, savedPolcompass.Field1QuestionQty)
+	assert.Equal(suite.T(), 1, savedPolcompass.Field2QuestionQty)
+	assert.Len(suite.T(), savedPolcompass.Questions, 3)
+
+	var questionCount int64
+	suite.DB.Model(&Question{}).Count(&questionCount)
+	assert.Equal(suite.T(), int64(3), questionCount)
+}
+
+func (suite *PolCompassUpdateTestSuite) TestPUT_UnknownField() {
+	requestBody := PolCompassReq{
+		Field1Name:  "Economic",
+		Field2Name:  "Social",
+		Name:        "Renamed Compass",
+		Description: "New Description",
+		Questions: []Question{
+			{Question: "Test Question", Affects: "Unknown", Direction: 1},
+		},
+	}
+
+	w := serveJSON(suite.router, "PUT", "/polcompass/1", requestBody)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	var savedPolcompass Polcompass
+	suite.DB.Preload("Questions").First(&savedPolcompass, suite.polcompass.ID)
+	assert.Equal(suite.T(), "Test Compass", savedPolcompass.Name)
+	assert.Len(suite.T(), savedPolcompass.Questions, 2)
+}
+
+func (suite *PolCompassUpdateTestSuite) TestPUT_NonExistentID() {
+	requestBody := PolCompassReq{Field1Name: "Economic", Field2Name: "Social", Name: "Missing Compass"}
+
+	w := serveJSON(suite.router, "PUT", "/polcompass/999", requestBody)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func (suite *PolCompassUpdateTestSuite) TestPATCH_Name() {
+	w := serveJSON(suite.router, "PATCH", "/polcompass/1", map[string]interface{}{"name": "Fixed typo"})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var savedPolcompass Polcompass
+	suite.DB.Preload("Questions").First(&savedPolcompass, suite.polcompass.ID)
+	assert.Equal(suite.T(), "Fixed typo", savedPolcompass.Name)
+	assert.Equal(suite.T(), "Test Description", savedPolcompass.Description)
+	assert.Equal(suite.T(), 1, savedPolcompass.Field1QuestionQty)
+	assert.Equal(suite.T(), 1, savedPolcompass.Field2QuestionQty)
+	assert.Len(suite.T(), savedPolcompass.Questions, 2)
+}
+
+func (suite *PolCompassUpdateTestSuite) TestPATCH_KeepsQuestionIDs() {
+	var before []Question
+	suite.DB.Order("id").Find(&before)
+
+	w := serveJSON(suite.router, "PATCH", "/polcompass/1", map[string]interface{}{"description": "new"})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var after []Question
+	suite.DB.Order("id").Find(&after)
+	assert.Equal(suite.T(), before, after)
+
+	w = serveJSON(suite.router, "PATCH", "/polcompass/1", map[string]interface{}{
+		"questions": []map[string]interface{}{
+			{"question": "Test Question 2", "affects": "Economic", "direction": 1},
+			{"question": "Test Question 3", "affects": "Social", "direction": 1},
+		},
+	})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	suite.DB.Order("id").Find(&after)
+	suite.Require().Len(after, 2)
+	assert.Equal(suite.T(), before[1].ID, after[0].ID)
+	assert.Equal(suite.T(), "Economic", after[0].Affects)
+	assert.Equal(suite.T(), "Test Question 3", after[1].Question)
+}
+
+func (suite *PolCompassUpdateTestSuite) TestPATCH_FieldNameRenamesQuestions() {
+	var before []Question
+	suite.DB.Order("id").Find(&before)
+
+	w := serveJSON(suite.router, "PATCH", "/polcompass/1", map[string]interface{}{"field1_name": "Economy"})
+	assert.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+
+	var savedPolcompass Polcompass
+	suite.DB.Preload("Questions", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).First(&savedPolcompass, suite.polcompass.ID)
+	assert.Equal(suite.T(), "Economy", savedPolcompass.Field1Name)
+	assert.Equal(suite.T(), 1, savedPolcompass.Field1QuestionQty)
+	suite.Require().Len(savedPolcompass.Questions, 2)
+	assert.Equal(suite.T(), before[0].ID, savedPolcompass.Questions[0].ID)
+	assert.Equal(suite.T(), "Economy", savedPolcompass.Questions[0].Affects)
+	assert.Equal(suite.T(), "Social", savedPolcompass.Questions[1].Affects)
+
+	w = serveJSON(suite.router, "PATCH", "/polcompass/1", map[string]interface{}{"field1_name": "Social", "field2_name": "Economy"})
+	assert.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+	suite.DB.Preload("Questions", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).First(&savedPolcompass, suite.polcompass.ID)
+	assert.Equal(suite.T(), "Social", savedPolcompass.Questions[0].Affects)
+	assert.Equal(suite.T(), "Economy", savedPolcompass.Questions[1].Affects)
+}
+
+func (suite *PolCompassUpdateTestSuite) TestPATCH_FieldNameOrphansSentQuestions() {
+	w := serveJSON(suite.router, "PATCH", "/polcompass/1", map[string]interface{}{
+		"field1_name": "Left",
+		"questions":   []map[string]interface{}{{"question": "Test Question 1", "affects": "Economic", "direction": 1}},
+	})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	var savedPolcompass Polcompass
+	suite.DB.First(&savedPolcompass, suite.polcompass.ID)
+	assert.Equal(suite.T(), "Economic", savedPolcompass.Field1Name)
+}
+
+func (suite *PolCompassUpdateTestSuite) TestPATCH_EmptyAxisName() {
+	single := Polcompass{Field1Name: "Economic", Name: "Single Axis Compass"}
+	suite.DB.Create(&single)
+
+	w := serveJSON(suite.router, "PATCH", fmt.Sprintf("/polcompass/%d", single.ID), map[string]interface{}{"name": "Renamed"})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	var response struct {
+		Code   string       `json:"code"`
+		Errors []FieldError `json:"details"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(suite.T(), "validation_failed", response.Code)
+	suite.Require().Len(response.Errors, 1)
+	assert.Equal(suite.T(), FieldError{Field: "axes[1].name", Rule: "required", Message: "is required"}, response.Errors[0])
+
+	w = serveJSON(suite.router, "PATCH", "/polcompass/1", map[string]interface{}{"field2_name": "Economic"})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &response)
+	suite.Require().Len(response.Errors, 1)
+	assert.Equal(suite.T(), "axes[1].name", response.Errors[0].Field)
+	assert.Equal(suite.T(), "unique", response.Errors[0].Rule)
+}
+
+func (suite *PolCompassUpdateTestSuite) TestPATCH_InvalidField() {
+	w := serveJSON(suite.router, "PATCH", "/polcompass/1", map[string]interface{}{
+		"name":      "",
+		"questions": []map[string]interface{}{{"question": "Test Question", "affects": "Economic", "direction": 2}},
+	})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	var response struct {
+		Errors []FieldError `json:"details"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	suite.Require().Len(response.Errors, 2)
+	assert.Equal(suite.T(), "name", response.Errors[0].Field)
+	assert.Equal(suite.T(), "questions[0].direction", response.Errors[1].Field)
+}
+
+func (suite *PolCompassUpdateTestSuite) TestPATCH_UnknownAxisWithOtherErrors() {
+	w := serveJSON(suite.router, "PATCH", "/polcompass/1", map[string]interface{}{
+		"name": "",
+		"questions": []map[string]interface{}{
+			{"question": "Test Question 1", "affects": "Economic", "direction": 1},
+			{"question": "Test Question 3", "effects": []map[string]interface{}{{"axis": "Cultural", "weight": 1}}},
+		},
+	})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	var response struct {
+		Errors []FieldError `json:"details"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	suite.Require().Len(response.Errors, 2)
+	assert.Equal(suite.T(), "name", response.Errors[0].Field)
+	assert.Equal(suite.T(), "questions[1].effects[0].axis", response.Errors[1].Field)
+}
+
+func (suite *PolCompassUpdateTestSuite) TestDELETE_AndRestore() {
+	w := serveJSON(suite.router, "DELETE", "/polcompass/1", nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var count int64
+	suite.DB.Model(&Polcompass{}).Count(&count)
+	assert.Equal(suite.T(), int64(0), count)
+
+	suite.DB.Unscoped().Model(&Polcompass{}).Count(&count)
+	assert.Equal(suite.T(), int64(1), count)
+
+	w = serveJSON(suite.router, "DELETE", "/polcompass/1", nil)
+	assert.Equal(suite.T(), http.StatusGone, w.Code)
+
+	w = serveJSON(suite.router, "POST", "/polcompass/1/restore", nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var response Polcompass
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(suite.T(), "Test Compass", response.Name)
+	assert.Len(suite.T(), response.Questions, 2)
+
+	w = serveJSON(suite.router, "POST", "/polcompass/1/restore", nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func (suite *PolCompassUpdateTestSuite) TestDELETE_InvalidID() {
+	w := serveJSON(suite.router, "DELETE", "/polcompass/invalid", nil)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func TestPolCompassUpdateSuite(t *testing.T) {
+	for _, database := range testDatabases() {
+		t.Run(database.name, func(t *testing.T) {
+			suite.Run(t, &PolCompassUpdateTestSuite{database: database})
+		})
+	}
+}
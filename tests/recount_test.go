@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"polcompass/backend/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type RecountTestSuite struct {
+	suite.Suite
+	database   testDatabase
+	DB         *gorm.DB
+	store      *models.GormStore
+	polcompass Polcompass
+}
+
+func (suite *RecountTestSuite) SetupTest() {
+	suite.DB = openTestDB(suite.T(), suite.database)
+	suite.store = models.NewGormStore(suite.DB)
+
+	suite.polcompass = Polcompass{
+		Name:              "Recount Compass",
+		Field1Name:        "Economic",
+		Field2Name:        "Social",
+		Field1QuestionQty: 1,
+		Field2QuestionQty: 1,
+		Axes:              []Axis{{Name: "Economic", QuestionQty: 1}, {Name: "Social", QuestionQty: 1}},
+		Questions: []Question{
+			{Question: "Economic Question", Affects: "Economic", Direction: 1},
+			{Question: "Social Question", Affects: "Social", Direction: -1},
+		},
+	}
+	suite.Require().NoError(suite.store.Create(testCtx, &suite.polcompass))
+}
+
+// editQuestions changes the questions table behind the back of the store.
+func (suite *RecountTestSuite) editQuestions() {
+	suite.Require().NoError(suite.DB.Where("question = ?", "Social Question").Delete(&Question{}).Error)
+	suite.Require().NoError(suite.DB.Create(&Question{
+		Question:     "Another Economic Question",
+		Affects:      "Economic",
+		Direction:    -1,
+		PolcompassID: suite.polcompass.ID,
+	}).Error)
+}
+
+func (suite *RecountTestSuite) TestGet_DerivesCounts() {
+	suite.editQuestions()
+
+	polcompass, err := suite.store.Get(testCtx, suite.polcompass.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), 2, polcompass.Field1QuestionQty)
+	assert.Equal(suite.T(), 0, polcompass.Field2QuestionQty)
+	suite.Require().Len(polcompass.Axes, 2)
+	assert.Equal(suite.T(), 2, polcompass.Axes[0].QuestionQty)
+	assert.Equal(suite.T(), 0, polcompass.Axes[1].QuestionQty)
+}
+
+func (suite *RecountTestSuite) TestAudit_ReportsAndRepairs() {
+	mismatches, err := suite.store.AuditQuestionCounts(testCtx, false)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), mismatches)
+
+	suite.editQuestions()
+	suite.Require().NoError(suite.store.Delete(testCtx, suite.polcompass.ID))
+
+	mismatches, err = suite.store.AuditQuestionCounts(testCtx, false)
+	suite.Require().NoError(err)
+	id := suite.polcompass.ID
+	assert.ElementsMatch(suite.T(), []models.CountMismatch{
+		{PolcompassID: id, Axis: "Economic", Column: "polcompasses.field1_question_qty", Stored: 1, Actual: 2},
+		{PolcompassID: id, Axis: "Social", Column: "polcompasses.field2_question_qty", Stored: 1, Actual: 0},
+		{PolcompassID: id, Axis: "Economic", Column: "axes.question_qty", Stored: 1, Actual: 2},
+		{PolcompassID: id, Axis: "Social", Column: "axes.question_qty", Stored: 1, Actual: 0},
+	}, mismatches)
+
+	mismatches, err = suite.store.AuditQuestionCounts(testCtx, true)
+	suite.Require().NoError(err)
+	assert.Len(suite.T(), mismatches, 4)
+
+	var stored Polcompass
+	suite.Require().NoError(suite.DB.Unscoped().Preload("Axes").First(&stored, id).Error)
+	assert.Equal(suite.T(), 2, stored.Field1QuestionQty)
+	assert.Equal(suite.T(), 0, stored.Field2QuestionQty)
+	assert.True(suite.T(), stored.UpdatedAt.Equal(suite.polcompass.UpdatedAt))
+
+	mismatches, err = suite.store.AuditQuestionCounts(testCtx, false)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), mismatches)
+}
+
+func TestRecountSuite(t *testing.T) {
+	for _, database := range testDatabases() {
+		t.Run(database.name, func(t *testing.T) {
+			suite.Run(t, &RecountTestSuite{database: database})
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"polcompass/backend/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ReferenceTestSuite struct {
+	suite.Suite
+	database   testDatabase
+	DB         *gorm.DB
+	router     *gin.Engine
+	polcompass Polcompass
+}
+
+func (suite *ReferenceTestSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+}
+
+func (suite *ReferenceTestSuite) SetupTest() {
+	suite.DB = openTestDB(suite.T(), suite.database)
+
+	controller := models.NewPolCompassController(models.NewGormStore(suite.DB))
+	suite.router = gin.New()
+	suite.router.POST("/polcompass", controller.POST)
+	suite.router.GET("/polcompass/:id", controller.GET)
+	suite.router.PUT("/polcompass/:id", controller.PUT)
+	suite.router.PATCH("/polcompass/:id", controller.PATCH)
+	suite.router.DELETE("/polcompass/:id", controller.DELETE)
+	suite.router.GET("/polcompass/:id/references", controller.ListReferences)
+	suite.router.POST("/polcompass/:id/references", controller.CreateReference)
+	suite.router.GET("/polcompass/:id/references/:referenceId", controller.GetReference)
+	suite.router.PUT("/polcompass/:id/references/:referenceId", controller.UpdateReference)
+	suite.router.DELETE("/polcompass/:id/references/:referenceId", controller.DeleteReference)
+
+	w := serveJSON(suite.router, "POST", "/polcompass", suite.compassReq())
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &suite.polcompass))
+}
+
+func (suite *ReferenceTestSuite) compassReq() PolCompassReq {
+	return PolCompassReq{
+		Field1Name: "Economic",
+		Field2Name: "Social",
+		Name:       "Reference Compass",
+		Questions: []Question{
+			{Question: "Economic Question", Affects: "Economic", Direction: 1},
+			{Question: "Social Question", Affects: "Social", Direction: -1},
+		},
+	}
+}
+
+func (suite *ReferenceTestSuite) code(w *httptest.ResponseRecorder) string {
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	code, _ := response["code"].(string)
+	return code
+}
+
+func (suite *ReferenceTestSuite) url(suffix string) string {
+	return fmt.Sprintf("/polcompass/%d/references%s", suite.polcompass.ID, suffix)
+}
+
+func (suite *ReferenceTestSuite) create(req ReferencePointReq) ReferencePoint {
+	w := serveJSON(suite.router, "POST", suite.url(""), req)
+	suite.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+
+	var reference ReferencePoint
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &reference))
+	return reference
+}
+
+func (suite *ReferenceTestSuite) TestCRUD() {
+	created := suite.create(ReferencePointReq{
+		Name:        "Green Party",
+		Description: "2024 manifesto",
+		Source:      "https://example.org/manifesto",
+		Scores:      []AxisScore{{Name: "Social", Score: -0.5}, {Name: "Economic", Score: -0.25}},
+	})
+	assert.NotZero(suite.T(), created.ID)
+	assert.Equal(suite.T(), -0.25, created.Field1)
+	assert.Equal(suite.T(), -0.5, created.Field2)
+	assert.Equal(suite.T(), []AxisScore{{Name: "Economic", Score: -0.25}, {Name: "Social", Score: -0.5}}, created.Scores)
+
+	w := serveJSON(suite.router, "GET", suite.url(fmt.Sprintf("/%d", created.ID)), nil)
+	suite.Require().Equal(http.StatusOK, w.Code)
+	var fetched ReferencePoint
+	json.Unmarshal(w.Body.Bytes(), &fetched)
+	assert.Equal(suite.T(), "https://example.org/manifesto", fetched.Source)
+
+	w = serveJSON(suite.router, "PUT", suite.url(fmt.Sprintf("/%d", created.ID)), ReferencePointReq{
+		Name:   "Green Party",
+		Scores: []AxisScore{{Name: "Economic", Score: 0.5}},
+	})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var updated ReferencePoint
+	json.Unmarshal(w.Body.Bytes(), &updated)
+	assert.Equal(suite.T(), 0.5, updated.Field1)
+	assert.Equal(suite.T(), 0.0, updated.Field2)
+	assert.Empty(suite.T(), updated.Description)
+
+	w = serveJSON(suite.router, "GET", suite.url(""), nil)
+	var references []ReferencePoint
+	json.Unmarshal(w.Body.Bytes(), &references)
+	suite.Require().Len(references, 1)
+	assert.Equal(suite.T(), 0.5, references[0].Field1)
+
+	w = serveJSON(suite.router, "DELETE", suite.url(fmt.Sprintf("/%d", created.ID)), nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	w = serveJSON(suite.router, "GET", suite.url(fmt.Sprintf("/%d", created.ID)), nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+	assert.Equal(suite.T(), "reference_not_found", suite.code(w))
+
+	w = serveJSON(suite.router, "DELETE", suite.url(fmt.Sprintf("/%d", created.ID)), nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func (suite *ReferenceTestSuite) TestCreate_FromAnswers() {
+	q := suite.polcompass.Questions
+	created := suite.create(ReferencePointReq{
+		Name:    "Public Figure",
+		Answers: []AnswerReq{{QuestionID: q[0].ID, Value: 2}, {QuestionID: q[1].ID, Value: 1}},
+	})
+	assert.Equal(suite.T(), 1.0, created.Field1)
+	assert.Equal(suite.T(), -0.5, created.Field2)
+	assert.Len(suite.T(), created.Answers, 2)
+}
+
+func (suite *ReferenceTestSuite) TestUpdateCompass_ReplotsAnswers() {
+	q := suite.polcompass.Questions
+	created := suite.create(ReferencePointReq{
+		Name:    "Public Figure",
+		Answers: []AnswerReq{{QuestionID: q[0].ID, Value: 2}, {QuestionID: q[1].ID, Value: 1}},
+	})
+	placed := suite.create(ReferencePointReq{Name: "Placed", Scores: []AxisScore{{Name: "Economic", Score: 0.5}}})
+
+	req := suite.compassReq()
+	req.Questions[0].Direction = -1
+	req.Questions = append(req.Questions, Question{Question: "Other Economic Question", Affects: "Economic", Direction: 1})
+	w := serveJSON(suite.router, "PUT", fmt.Sprintf("/polcompass/%d", suite.polcompass.ID), req)
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	var polcompass Polcompass
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &polcompass))
+	suite.Require().Len(polcompass.References, 2)
+	assert.Equal(suite.T(), created.ID, polcompass.References[0].ID)
+	assert.Equal(suite.T(), -0.5, polcompass.References[0].Field1)
+	assert.Equal(suite.T(), -0.5, polcompass.References[0].Field2)
+	assert.Equal(suite.T(), placed.Field1, polcompass.References[1].Field1)
+
+	// the answer of 2 is off a -1..1 scale and counts as unanswered
+	w = serveJSON(suite.router, "PUT", fmt.Sprintf("/polcompass/%d", suite.polcompass.ID), PolCompassReq{
+		Field1Name: "Economic",
+		Field2Name: "Social",
+		Name:       "Reference Compass",
+		Questions:  suite.compassReq().Questions,
+		Scale:      &Scale{Options: []ScaleOption{{Label: "No", Value: -1}, {Label: "Maybe", Value: 0}, {Label: "Yes", Value: 1}}},
+	})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	w = serveJSON(suite.router, "GET", suite.url(fmt.Sprintf("/%d", created.ID)), nil)
+	var replotted ReferencePoint
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &replotted))
+	assert.Equal(suite.T(), 0.0, replotted.Field1)
+	assert.Equal(suite.T(), -1.0, replotted.Field2)
+}
+
+func (suite *ReferenceTestSuite) TestPatchCompass_MovesScores() {
+	placed := suite.create(ReferencePointReq{Name: "Placed", Scores: []AxisScore{{Name: "Economic", Score: 0.5}, {Name: "Social", Score: -0.25}}})
+
+	w := serveJSON(suite.router, "PATCH", fmt.Sprintf("/polcompass/%d", suite.polcompass.ID), map[string]interface{}{"field1_name": "Market"})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	w = serveJSON(suite.router, "GET", suite.url(fmt.Sprintf("/%d", placed.ID)), nil)
+	var renamed ReferencePoint
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &renamed))
+	assert.Equal(suite.T(), []AxisScore{{Name: "Market", Score: 0.5}, {Name: "Social", Score: -0.25}}, renamed.Scores)
+	assert.Equal(suite.T(), 0.5, renamed.Field1)
+	assert.Equal(suite.T(), -0.25, renamed.Field2)
+
+	// the score on a removed axis goes away with it
+	w = serveJSON(suite.router, "PATCH", fmt.Sprintf("/polcompass/%d", suite.polcompass.ID), map[string]interface{}{
+		"axes":      []Axis{{Name: "Social"}},
+		"questions": []Question{{Question: "Social Question", Affects: "Social", Direction: -1}},
+	})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	w = serveJSON(suite.router, "GET", suite.url(fmt.Sprintf("/%d", placed.ID)), nil)
+	var removed ReferencePoint
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &removed))
+	assert.Equal(suite.T(), []AxisScore{{Name: "Social", Score: -0.25}}, removed.Scores)
+	assert.Equal(suite.T(), -0.25, removed.Field1)
+	assert.Equal(suite.T(), 0.0, removed.Field2)
+}
+
+func (suite *ReferenceTestSuite) TestGET_IncludesReferences() {
+	suite.create(ReferencePointReq{Name: "First", Scores: []AxisScore{{Name: "Economic", Score: 1}}})
+	suite.create(ReferencePointReq{Name: "Second", Scores: []AxisScore{{Name: "Social", Score: 1}}})
+
+	// saving the polcompass again keeps its reference points
+	w := serveJSON(suite.router, "PUT", fmt.Sprintf("/polcompass/%d", suite.polcompass.ID), suite.compassReq())
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	w = serveJSON(suite.router, "GET", fmt.Sprintf("/polcompass/%d", suite.polcompass.ID), nil)
+	var polcompass Polcompass
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &polcompass))
+	suite.Require().Len(polcompass.References, 2)
+	assert.Equal(suite.T(), "First", polcompass.References[0].Name)
+	assert.Equal(suite.T(), 1.0, polcompass.References[1].Field2)
+}
+
+func (suite *ReferenceTestSuite) TestCreate_Invalid() {
+	q := suite.polcompass.Questions
+	tests := []struct {
+		name string
+		req  ReferencePointReq
+		code string
+	}{
+		{"no name", ReferencePointReq{Scores: []AxisScore{{Name: "Economic", Score: 1}}}, "validation_failed"},
+		{"no position", ReferencePointReq{Name: "Nowhere"}, "validation_failed"},
+		{"scores and answers", ReferencePointReq{Name: "Both", Scores: []AxisScore{{Name: "Economic", Score: 1}}, Answers: []AnswerReq{{QuestionID: q[0].ID, Value: 1}}}, "validation_failed"},
+		{"unknown axis", ReferencePointReq{Name: "Unknown", Scores: []AxisScore{{Name: "Cultural", Score: 1}}}, "unknown_axis"},
+		{"out of range", ReferencePointReq{Name: "Far", Scores: []AxisScore{{Name: "Economic", Score: 1.5}}}, "validation_failed"},
+		{"duplicate axis", ReferencePointReq{Name: "Twice", Scores: []AxisScore{{Name: "Economic", Score: 1}, {Name: "Economic", Score: 0}}}, "invalid_axes"},
+		{"unknown question", ReferencePointReq{Name: "Lost", Answers: []AnswerReq{{QuestionID: 999, Value: 1}}}, "invalid_answer"},
+	}
+	for _, tt := range tests {
+		w := serveJSON(suite.router, "POST", suite.url(""), tt.req)
+		assert.Equal(suite.T(), http.StatusBadRequest, w.Code, tt.name)
+		assert.Equal(suite.T(), tt.code, suite.code(w), tt.name)
+	}
+}
+
+func (suite *ReferenceTestSuite) TestDeletedCompass() {
+	created := suite.create(ReferencePointReq{Name: "Gone", Scores: []AxisScore{{Name: "Economic", Score: 1}}})
+	suite.Require().Equal(http.StatusOK, serveJSON(suite.router, "DELETE", fmt.Sprintf("/polcompass/%d", suite.polcompass.ID), nil).Code)
+
+	w := serveJSON(suite.router, "GET", suite.url(fmt.Sprintf("/%d", created.ID)), nil)
+	assert.Equal(suite.T(), http.StatusGone, w.Code)
+
+	w = serveJSON(suite.router, "GET", "/polcompass/999/references", nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func TestReferenceTestSuite(t *testing.T) {
+	for _, database := range testDatabases() {
+		t.Run(database.name, func(t *testing.T) {
+			suite.Run(t, &ReferenceTestSuite{database: database})
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"polcompass/backend/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type RegionTestSuite struct {
+	suite.Suite
+	database   testDatabase
+	DB         *gorm.DB
+	router     *gin.Engine
+	polcompass Polcompass
+}
+
+func (suite *RegionTestSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+}
+
+func (suite *RegionTestSuite) SetupTest() {
+	suite.DB = openTestDB(suite.T(), suite.database)
+
+	controller := models.NewPolCompassController(models.NewGormStore(suite.DB))
+	suite.router = gin.New()
+	suite.router.POST("/polcompass", controller.POST)
+	suite.router.GET("/polcompass/:id", controller.GET)
+	suite.router.POST("/polcompass/:id/score", controller.Score)
+
+	w := serveJSON(suite.router, "POST", "/polcompass", PolCompassReq{
+		Field1Name: "Economic",
+		Field2Name: "Social",
+		Name:       "Region Compass",
+		Questions: []Question{
+			{Question: "Economic Question 1", Affects: "Economic", Direction: 1},
+			{Question: "Economic Question 2", Affects: "Economic", Direction: 1},
+			{Question: "Social Question 1", Affects: "Social", Direction: 1},
+			{Question: "Social Question 2", Affects: "Social", Direction: 1},
+		},
+		Regions: []Region{
+			{Title: "Centrist", Description: "Close to the middle", Rectangle: &Rectangle{MinX: -0.25, MinY: -0.25, MaxX: 0.25, MaxY: 0.25}},
+			{Title: "Libertarian Left", Polygon: []Point{{X: -1, Y: -1}, {X: 0, Y: -1}, {X: -1, Y: 0}}},
+			{Title: "Authoritarian Right", Quadrant: "top_right"},
+		},
+	})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &suite.polcompass))
+}
+
+// archetype scores the answers given to the two economic and the two
+// social questions.
+func (suite *RegionTestSuite) archetype(economic [2]int, social [2]int) *Region {
+	q := suite.polcompass.Questions
+	w := serveJSON(suite.router, "POST", fmt.Sprintf("/polcompass/%d/score", suite.polcompass.ID), ScoreReq{Answers: []AnswerReq{
+		{QuestionID: q[0].ID, Value: economic[0]},
+		{QuestionID: q[1].ID, Value: economic[1]},
+		{QuestionID: q[2].ID, Value: social[0]},
+		{QuestionID: q[3].ID, Value: social[1]},
+	}})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	var score ScoreResponse
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &score))
+	return score.Archetype
+}
+
+func (suite *RegionTestSuite) TestGET_ReturnsTheRegions() {
+	w := serveJSON(suite.router, "GET", fmt.Sprintf("/polcompass/%d", suite.polcompass.ID), nil)
+	var polcompass Polcompass
+	json.Unmarshal(w.Body.Bytes(), &polcompass)
+	suite.Require().Len(polcompass.Regions, 3)
+	assert.Equal(suite.T(), "Close to the middle", polcompass.Regions[0].Description)
+	assert.Equal(suite.T(), &Rectangle{MinX: -0.25, MinY: -0.25, MaxX: 0.25, MaxY: 0.25}, polcompass.Regions[0].Rectangle)
+	assert.Len(suite.T(), polcompass.Regions[1].Polygon, 3)
+}
+
+func (suite *RegionTestSuite) TestScore_Archetypes() {
+	// the first matching region wins, the centre is in the top right quadrant too
+	assert.Equal(suite.T(), "Centrist", suite.archetype([2]int{0, 0}, [2]int{0, 0}).Title)
+	assert.Equal(suite.T(), "Authoritarian Right", suite.archetype([2]int{2, 2}, [2]int{1, 0}).Title)
+	assert.Equal(suite.T(), "Libertarian Left", suite.archetype([2]int{-2, -1}, [2]int{-2, -1}).Title)
+
+	// outside the triangle of the libertarian left
+	assert.Nil(suite.T(), suite.archetype([2]int{-1, 0}, [2]int{-2, 0}))
+	assert.Nil(suite.T(), suite.archetype([2]int{-2, -2}, [2]int{2, 2}))
+}
+
+func (suite *RegionTestSuite) TestPOST_InvalidRegions() {
+	w := serveJSON(suite.router, "POST", "/polcompass", PolCompassReq{
+		Field1Name: "Economic",
+		Field2Name: "Social",
+		Name:       "Invalid Regions",
+		Regions: []Region{
+			{Title: "No Shape"},
+			{Title: "Two Shapes", Quadrant: "top_left", Rectangle: &Rectangle{MaxX: 1, MaxY: 1}},
+			{Title: "Unknown Quadrant", Quadrant: "middle"},
+			{Title: "Flat Rectangle", Rectangle: &Rectangle{MinX: 0.5, MaxX: 0.5, MaxY: 1}},
+			{Title: "Segment", Polygon: []Point{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+			{Quadrant: "top_left"},
+		},
+	})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	var apiErr struct {
+		Details []FieldError `json:"details"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &apiErr)
+	var fields []string
+	for _, fieldError := range apiErr.Details {
+		fields = append(fields, fieldError.Field)
+	}
+	assert.ElementsMatch(suite.T(), []string{
+		"regions[0].quadrant",
+		"regions[1].quadrant",
+		"regions[2].quadrant",
+		"regions[3].rectangle.max_x",
+		"regions[4].polygon",
+		"regions[5].title",
+	}, fields)
+}
+
+func TestRegionSuite(t *testing.T) {
+	for _, database := range testDatabases() {
+		t.Run(database.name, func(t *testing.T) {
+			suite.Run(t, &RegionTestSuite{database: database})
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"polcompass/backend/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type ResponseTestSuite struct {
+	suite.Suite
+	DB         *gorm.DB
+	controller *PolCompassController
+	router     *gin.Engine
+	questions  []Question
+}
+
+func (suite *ResponseTestSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+}
+
+func (suite *ResponseTestSuite) SetupTest() {
+	var err error
+	suite.DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	err = suite.DB.AutoMigrate(&Polcompass{}, &Axis{}, &Question{}, &Response{}, &Answer{}, &ReferencePoint{}, &ReferenceAnswer{})
+	suite.Require().NoError(err)
+
+	suite.controller = models.NewPolCompassController(models.NewGormStore(suite.DB))
+	suite.router = gin.New()
+
+	suite.router.POST("/polcompass/:id/responses", suite.controller.Submit)
+	suite.router.GET("/responses/:publicId", suite.controller.GetResponse)
+
+	polcompass := Polcompass{
+		Field1Name:        "Economic",
+		Field2Name:        "Social",
+		Field1QuestionQty: 1,
+		Field2QuestionQty: 1,
+		Name:              "Response Compass",
+		Description:       "Response Description",
+	}
+	suite.DB.Create(&polcompass)
+
+	suite.questions = []Question{
+		{Question: "Economic Question", Affects: "Economic", Direction: 1, PolcompassID: polcompass.ID},
+		{Question: "Social Question", Affects: "Social", Direction: -1, PolcompassID: polcompass.ID},
+	}
+	suite.DB.Create(&suite.questions)
+}
+
+func (suite *ResponseTestSuite) TearDownTest() {
+	sqlDB, _ := suite.DB.DB()
+	sqlDB.Close()
+}
+
+func (suite *ResponseTestSuite) TestSubmitAndRetrieve() {
+	jsonData, _ := json.Marshal(ScoreReq{Answers: []AnswerReq{
+		{QuestionID: suite.questions[0].ID, Value: 2},
+		{QuestionID: suite.questions[1].ID, Value: 1},
+	}})
+	req, _ := http.NewRequest("POST", "/polcompass/1/responses", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+
+	var created Response
+	json.Unmarshal(w.Body.Bytes(), &created)
+	assert.Len(suite.T(), created.PublicID, 32)
+	assert.Equal(suite.T(), 1.0, created.Field1)
+	assert.Equal(suite.T(), -0.5, created.Field2)
+
+	req, _ = http.NewRequest("GET", "/responses/"+created.PublicID, nil)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var retrieved Response
+	json.Unmarshal(w.Body.Bytes(), &retrieved)
+	assert.Equal(suite.T(), created.PublicID, retrieved.PublicID)
+	assert.Equal(suite.T(), uint(1), retrieved.PolcompassID)
+	assert.Equal(suite.T(), 1.0, retrieved.Field1)
+	assert.Len(suite.T(), retrieved.Answers, 2)
+}
+
+func (suite *ResponseTestSuite) TestSubmit_InvalidAnswer() {
+	jsonData, _ := json.Marshal(ScoreReq{Answers: []AnswerReq{{QuestionID: 999, Value: 1}}})
+	req, _ := http.NewRequest("POST", "/polcompass/1/responses", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	var count int64
+	suite.DB.Model(&Response{}).Count(&count)
+	assert.Equal(suite.T(), int64(0), count)
+}
+
+func (suite *ResponseTestSuite) TestGetResponse_NotFound() {
+	req, _ := http.NewRequest("GET", "/responses/doesnotexist", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func TestResponseSuite(t *testing.T) {
+	suite.Run(t, new(ResponseTestSuite))
+}
@@ -0,0 +1,224 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"polcompass/backend/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ScaleTestSuite struct {
+	suite.Suite
+	database testDatabase
+	DB       *gorm.DB
+	router   *gin.Engine
+}
+
+func (suite *ScaleTestSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+}
+
+func (suite *ScaleTestSuite) SetupTest() {
+	suite.DB = openTestDB(suite.T(), suite.database)
+
+	controller := models.NewPolCompassController(models.NewGormStore(suite.DB))
+	suite.router = gin.New()
+	suite.router.POST("/polcompass", controller.POST)
+	suite.router.GET("/polcompass/:id", controller.GET)
+	suite.router.PATCH("/polcompass/:id", controller.PATCH)
+	suite.router.POST("/polcompass/:id/score", controller.Score)
+	suite.router.POST("/polcompass/:id/responses", controller.Submit)
+}
+
+// sevenPoints is a 1 to 7 scale, 4 being neutral.
+func sevenPoints() *Scale {
+	scale := &Scale{AllowSkip: true, SkipLabel: "Don't know"}
+	labels := []string{"Strongly disagree", "Disagree", "Somewhat disagree", "Neutral", "Somewhat agree", "Agree", "Strongly agree"}
+	for i, label := range labels {
+		scale.Options = append(scale.Options, ScaleOption{Label: label, Value: i + 1})
+	}
+	return scale
+}
+
+func (suite *ScaleTestSuite) create(scale *Scale) Polcompass {
+	w := serveJSON(suite.router, "POST", "/polcompass", PolCompassReq{
+		Field1Name: "Economic",
+		Field2Name: "Social",
+		Name:       "Likert Compass",
+		Questions: []Question{
+			{Question: "Economic Question 1", Affects: "Economic", Direction: 1},
+			{Question: "Economic Question 2", Affects: "Economic", Direction: -1},
+			{Question: "Social Question", Affects: "Social", Direction: 1},
+		},
+		Scale: scale,
+	})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	var polcompass Polcompass
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &polcompass))
+	return polcompass
+}
+
+func (suite *ScaleTestSuite) score(polcompass Polcompass, answers []AnswerReq) (*httptest.ResponseRecorder, ScoreResponse) {
+	w := serveJSON(suite.router, "POST", fmt.Sprintf("/polcompass/%d/score", polcompass.ID), ScoreReq{Answers: answers})
+	var score ScoreResponse
+	json.Unmarshal(w.Body.Bytes(), &score)
+	return w, score
+}
+
+func (suite *ScaleTestSuite) TestGET_ReturnsTheScale() {
+	created := suite.create(sevenPoints())
+
+	w := serveJSON(suite.router, "GET", fmt.Sprintf("/polcompass/%d", created.ID), nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var polcompass Polcompass
+	json.Unmarshal(w.Body.Bytes(), &polcompass)
+	assert.Equal(suite.T(), *sevenPoints(), polcompass.Scale)
+}
+
+func (suite *ScaleTestSuite) TestGET_DefaultScale() {
+	created := suite.create(nil)
+	assert.Equal(suite.T(), models.DefaultScale(), created.Scale)
+
+	// polcompasses saved before scales existed have no scale stored
+	suite.Require().NoError(suite.DB.Exec("UPDATE polcompasses SET scale = NULL").Error)
+	w := serveJSON(suite.router, "GET", fmt.Sprintf("/polcompass/%d", created.ID), nil)
+	var polcompass Polcompass
+	json.Unmarshal(w.Body.Bytes(), &polcompass)
+	assert.Equal(suite.T(), models.DefaultScale(), polcompass.Scale)
+}
+
+func (suite *ScaleTestSuite) TestScore_SevenPoints() {
+	polcompass := suite.create(sevenPoints())
+	q := polcompass.Questions
+
+	w, score := suite.score(polcompass, []AnswerReq{
+		{QuestionID: q[0].ID, Value: 7},
+		{QuestionID: q[1].ID, Value: 4},
+		{QuestionID: q[2].ID, Value: 2},
+	})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	// 3 points above neutral out of 6, and 2 below out of 3
+	assert.InDelta(suite.T(), 0.5, score.Field1, 1e-9)
+	assert.InDelta(suite.T(), -2.0/3, score.Field2, 1e-9)
+}
+
+func (suite *ScaleTestSuite) TestScore_SkipsAreExcluded() {
+	polcompass := suite.create(sevenPoints())
+	q := polcompass.Questions
+
+	w, score := suite.score(polcompass, []AnswerReq{
+		{QuestionID: q[0].ID, Value: 7},
+		{QuestionID: q[1].ID, Skipped: true},
+		{QuestionID: q[2].ID, Skipped: true},
+	})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(suite.T(), 1.0, score.Field1)
+	assert.Equal(suite.T(), 0.0, score.Field2)
+
+	w = serveJSON(suite.router, "POST", fmt.Sprintf("/polcompass/%d/responses", polcompass.ID), ScoreReq{Answers: []AnswerReq{
+		{QuestionID: q[1].ID, Value: 3, Skipped: true},
+	}})
+	suite.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	var response Response
+	json.Unmarshal(w.Body.Bytes(), &response)
+	suite.Require().Len(response.Answers, 1)
+	assert.True(suite.T(), response.Answers[0].Skipped)
+	assert.Equal(suite.T(), 0, response.Answers[0].Value)
+}
+
+func (suite *ScaleTestSuite) TestScore_SkippedAndUnanswered() {
+	polcompass := suite.create(sevenPoints())
+	q := polcompass.Questions
+
+	w, score := suite.score(polcompass, []AnswerReq{
+		{QuestionID: q[0].ID, Value: 7},
+		{QuestionID: q[2].ID, Skipped: true},
+	})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	// the unanswered question still counts in the strongest economic score
+	assert.Equal(suite.T(), 0.5, score.Field1)
+	assert.Equal(suite.T(), []SkippedQuestion{{QuestionID: q[2].ID, Question: "Social Question"}}, score.Skipped)
+	assert.Equal(suite.T(), []SkippedQuestion{{QuestionID: q[1].ID, Question: "Economic Question 2"}}, score.Unanswered)
+}
+
+func (suite *ScaleTestSuite) TestScore_InvalidAnswers() {
+	polcompass := suite.create(sevenPoints())
+	q := polcompass.Questions
+
+	w, _ := suite.score(polcompass, []AnswerReq{{QuestionID: q[0].ID, Value: 0}})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "must be one of 1, 2, 3, 4, 5, 6, 7")
+
+	defaultScale := suite.create(nil)
+	w, _ = suite.score(defaultScale, []AnswerReq{{QuestionID: defaultScale.Questions[0].ID, Skipped: true}})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "cannot be skipped")
+}
+
+func (suite *ScaleTestSuite) TestPOST_InvalidScale() {
+	w := serveJSON(suite.router, "POST", "/polcompass", PolCompassReq{
+		Field1Name: "Economic",
+		Field2Name: "Social",
+		Name:       "Likert Compass",
+		Scale: &Scale{Options: []ScaleOption{
+			{Label: "Disagree", Value: -1},
+			{Label: "Agree", Value: -1},
+			{Label: "Agree", Value: 1},
+			{Value: 2},
+		}},
+	})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	var apiErr struct {
+		Details []FieldError `json:"details"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &apiErr)
+	var fields []string
+	for _, fieldError := range apiErr.Details {
+		fields = append(fields, fieldError.Field)
+	}
+	assert.ElementsMatch(suite.T(), []string{"scale.options[3].label", "scale.options[1].value", "scale.options[2].label"}, fields)
+
+	w = serveJSON(suite.router, "POST", "/polcompass", PolCompassReq{
+		Field1Name: "Economic",
+		Field2Name: "Social",
+		Name:       "Likert Compass",
+		Scale:      &Scale{Options: []ScaleOption{{Label: "Agree", Value: 1}}},
+	})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "scale.options")
+}
+
+func (suite *ScaleTestSuite) TestPATCH_KeepsOrReplacesTheScale() {
+	polcompass := suite.create(sevenPoints())
+	url := fmt.Sprintf("/polcompass/%d", polcompass.ID)
+
+	w := serveJSON(suite.router, "PATCH", url, map[string]string{"name": "Renamed"})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var patched Polcompass
+	json.Unmarshal(w.Body.Bytes(), &patched)
+	assert.Equal(suite.T(), *sevenPoints(), patched.Scale)
+
+	w = serveJSON(suite.router, "PATCH", url, map[string]interface{}{"scale": models.DefaultScale()})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var replaced Polcompass
+	json.Unmarshal(w.Body.Bytes(), &replaced)
+	assert.Equal(suite.T(), models.DefaultScale(), replaced.Scale)
+}
+
+func TestScaleSuite(t *testing.T) {
+	for _, database := range testDatabases() {
+		t.Run(database.name, func(t *testing.T) {
+			suite.Run(t, &ScaleTestSuite{database: database})
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"polcompass/backend/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type ScoreTestSuite struct {
+	suite.Suite
+	DB         *gorm.DB
+	controller *PolCompassController
+	router     *gin.Engine
+	questions  []Question
+}
+
+func (suite *ScoreTestSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+}
+
+func (suite *ScoreTestSuite) SetupTest() {
+	var err error
+	suite.DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	err = suite.DB.AutoMigrate(&Polcompass{}, &Axis{}, &Question{}, &ReferencePoint{}, &ReferenceAnswer{})
+	suite.Require().NoError(err)
+
+	suite.controller = models.NewPolCompassController(models.NewGormStore(suite.DB))
+	suite.router = gin.New()
+
+	suite.router.POST("/polcompass/:id/score", suite.controller.Score)
+
+	polcompass := Polcompass{
+		Field1Name:        "Economic",
+		Field2Name:        "Social",
+		Field1QuestionQty: 2,
+		Field2QuestionQty: 2,
+		Name:              "Score Compass",
+		Description:       "Score Description",
+	}
+	suite.DB.Create(&polcompass)
+
+	suite.questions = []Question{
+		{Question: "Economic Question 1", Affects: "Economic", Direction: 1, PolcompassID: polcompass.ID},
+		{Question: "Economic Question 2", Affects: "Economic", Direction: -1, PolcompassID: polcompass.ID},
+		{Question: "Social Question 1", Affects: "Social", Direction: 1, PolcompassID: polcompass.ID},
+		{Question: "Social Question 2", Affects: "Social", Direction: -1, PolcompassID: polcompass.ID},
+	}
+	suite.DB.Create(&suite.questions)
+}
+
+func (suite *ScoreTestSuite) TearDownTest() {
+	sqlDB, _ := suite.DB.DB()
+	sqlDB.Close()
+}
+
+func (suite *ScoreTestSuite) score(url string, answers []AnswerReq) *httptest.ResponseRecorder {
+	jsonData, _ := json.Marshal(ScoreReq{Answers: answers})
+	req, _ := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *ScoreTestSuite) TestScore_Extremes() {
+	w := suite.score("/polcompass/1/score", []AnswerReq{
+		{QuestionID: suite.questions[0].ID, Value: 2},
+		{QuestionID: suite.questions[1].ID, Value: -2},
+		{QuestionID: suite.questions[2].ID, Value: -2},
+		{QuestionID: suite.questions[3].ID, Value: 2},
+	})
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var response ScoreResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(suite.T(), "Economic", response.Field1Name)
+	assert.Equal(suite.T(), "Social", response.Field2Name)
+	assert.Equal(suite.T(), 1.0, response.Field1)
+	assert.Equal(suite.T(), -1.0, response.Field2)
+}
+
+func (suite *ScoreTestSuite) TestScore_Partial() {
+	w := suite.score("/polcompass/1/score", []AnswerReq{
+		{QuestionID: suite.questions[0].ID, Value: 1},
+		{QuestionID: suite.questions[2].ID, Value: 0},
+	})
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var response ScoreResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(suite.T(), 0.25, response.Field1)
+	assert.Equal(suite.T(), 0.0, response.Field2)
+}
+
+func (suite *ScoreTestSuite) TestScore_Contributions() {
+	w := suite.score("/polcompass/1/score", []AnswerReq{
+		{QuestionID: suite.questions[0].ID, Value: 1},
+		{QuestionID: suite.questions[1].ID, Value: 2},
+		{QuestionID: suite.questions[2].ID, Value: 0},
+	})
+
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var response ScoreResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(suite.T(), -0.25, response.Field1)
+	suite.Require().Len(response.Contributions, 2)
+
+	economic := response.Contributions[0]
+	assert.Equal(suite.T(), "Economic", economic.Axis)
+	assert.Equal(suite.T(), []QuestionContribution{
+		{QuestionID: suite.questions[1].ID, Question: "Economic Question 2", Value: 2, Weight: -1, Contribution: -0.5},
+		{QuestionID: suite.questions[0].ID, Question: "Economic Question 1", Value: 1, Weight: 1, Contribution: 0.25},
+	}, economic.Questions)
+
+	social := response.Contributions[1]
+	suite.Require().Len(social.Questions, 1)
+	assert.Equal(suite.T(), 0.0, social.Questions[0].Contribution)
+
+	assert.Empty(suite.T(), response.Skipped)
+	assert.Equal(suite.T(), []SkippedQuestion{{QuestionID: suite.questions[3].ID, Question: "Social Question 2"}}, response.Unanswered)
+}
+
+func (suite *ScoreTestSuite) TestScore_UnknownQuestion() {
+	w := suite.score("/polcompass/1/score", []AnswerReq{{QuestionID: 999, Value: 1}})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func (suite *ScoreTestSuite) TestScore_DuplicateAnswer() {
+	w := suite.score("/polcompass/1/score", []AnswerReq{
+		{QuestionID: suite.questions[0].ID, Value: 1},
+		{QuestionID: suite.questions[0].ID, Value: 2},
+	})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func (suite *ScoreTestSuite) TestScore_ValueOutOfRange() {
+	w := suite.score("/polcompass/1/score", []AnswerReq{{QuestionID: suite.questions[0].ID, Value: 3}})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func (suite *ScoreTestSuite) TestScore_NonExistentCompass() {
+	w := suite.score("/polcompass/999/score", []AnswerReq{})
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func TestScoreSuite(t *testing.T) {
+	suite.Run(t, new(ScoreTestSuite))
+}
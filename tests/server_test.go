@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"polcompass/backend/config"
+	"polcompass/backend/server"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowServer serves a handler taking delay to answer and returns the url of
+// the server, a channel receiving the request start and the result of Serve.
+func slowServer(t *testing.T, ctx context.Context, delay time.Duration, shutdownTimeout time.Duration) (string, chan struct{}, chan error) {
+	started := make(chan struct{}, 1)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		time.Sleep(delay)
+		io.WriteString(w, "done")
+	})
+
+	cfg := config.Default().Server
+	cfg.ShutdownTimeout = config.Duration{Duration: shutdownTimeout}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- server.Serve(ctx, server.New(handler, cfg), listener, cfg)
+	}()
+
+	return "http://" + listener.Addr().String(), started, stopped
+}
+
+func TestServer_DrainsRequestsInFlight(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	url, started, stopped := slowServer(t, ctx, 200*time.Millisecond, time.Second)
+
+	responses := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			responses <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		responses <- string(body)
+	}()
+
+	<-started
+	cancel()
+
+	assert.Equal(t, "done", <-responses)
+	assert.NoError(t, <-stopped)
+
+	_, err := http.Get(url)
+	assert.Error(t, err, "the server should not accept connections after shutdown")
+}
+
+func TestServer_ShutdownDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	url, started, stopped := slowServer(t, ctx, time.Second, 50*time.Millisecond)
+
+	go http.Get(url)
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-stopped:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("the server did not give up on the slow request")
+	}
+}
+
+func TestServer_Timeouts(t *testing.T) {
+	cfg := config.Default().Server
+	srv := server.New(http.NotFoundHandler(), cfg)
+	assert.Equal(t, cfg.Addr, srv.Addr)
+	assert.Equal(t, 15*time.Second, srv.ReadTimeout)
+	assert.Equal(t, 5*time.Second, srv.ReadHeaderTimeout)
+	assert.Equal(t, 30*time.Second, srv.WriteTimeout)
+	assert.Equal(t, 2*time.Minute, srv.IdleTimeout)
+}
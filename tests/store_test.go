@@ -0,0 +1,352 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"polcompass/backend/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+var testCtx = context.Background()
+
+// StoreTestSuite checks that every CompassStore behaves the same way,
+// newStore returns an empty store.
+type StoreTestSuite struct {
+	suite.Suite
+	newStore func() (CompassStore, func())
+	store    CompassStore
+	close    func()
+}
+
+func (suite *StoreTestSuite) SetupTest() {
+	suite.store, suite.close = suite.newStore()
+}
+
+func (suite *StoreTestSuite) TearDownTest() {
+	suite.close()
+}
+
+func (suite *StoreTestSuite) create(name string, description string) Polcompass {
+	polcompass := Polcompass{
+		Name:        name,
+		Description: description,
+		Axes:        []Axis{{Name: "Economic"}, {Name: "Social"}},
+		Questions: []Question{
+			{Question: name + " Question 1", Affects: "Economic", Direction: 1},
+			{Question: name + " Question 2", Effects: []Effect{{Axis: "Social", Weight: -0.5}}},
+		},
+	}
+	suite.Require().NoError(suite.store.Create(testCtx, &polcompass))
+	return polcompass
+}
+
+func (suite *StoreTestSuite) errorCode(err error) string {
+	apiErr, ok := err.(*models.APIError)
+	suite.Require().True(ok, "expected an APIError, got %v", err)
+	return apiErr.Code
+}
+
+func (suite *StoreTestSuite) TestCreateAndGet() {
+	created := suite.create("Store Compass", "Store Description")
+	assert.NotZero(suite.T(), created.ID)
+	suite.Require().Len(created.Axes, 2)
+	suite.Require().Len(created.Questions, 2)
+	assert.NotZero(suite.T(), created.Questions[0].ID)
+	assert.Equal(suite.T(), "Social", created.Questions[1].Affects)
+	assert.Equal(suite.T(), -1, created.Questions[1].Direction)
+
+	polcompass, err := suite.store.Get(testCtx, created.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "Store Compass", polcompass.Name)
+	suite.Require().Len(polcompass.Axes, 2)
+	assert.Equal(suite.T(), "Economic", polcompass.Axes[0].Name)
+	assert.Equal(suite.T(), "Social", polcompass.Axes[1].Name)
+	suite.Require().Len(polcompass.Questions, 2)
+	assert.Equal(suite.T(), []Effect{{Axis: "Social", Weight: -0.5}}, polcompass.Questions[1].Effects)
+
+	first, err := suite.store.First(testCtx)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), created.ID, first.ID)
+}
+
+func (suite *StoreTestSuite) TestGet_Unknown() {
+	_, err := suite.store.Get(testCtx, 999)
+	assert.Equal(suite.T(), "compass_not_found", suite.errorCode(err))
+
+	_, err = suite.store.First(testCtx)
+	assert.Equal(suite.T(), "compass_not_found", suite.errorCode(err))
+}
+
+func (suite *StoreTestSuite) TestUpdate_ReplacesAxesAndQuestions() {
+	polcompass := suite.create("Store Compass", "Store Description")
+
+	polcompass.Name = "Renamed"
+	polcompass.Axes = []Axis{{Name: "Left"}}
+	polcompass.Questions = []Question{{Question: "Left Question", Affects: "Left", Direction: -1}}
+	suite.Require().NoError(suite.store.Update(testCtx, &polcompass, nil))
+
+	saved, err := suite.store.Get(testCtx, polcompass.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "Renamed", saved.Name)
+	suite.Require().Len(saved.Axes, 1)
+	assert.Equal(suite.T(), "Left", saved.Axes[0].Name)
+	suite.Require().Len(saved.Questions, 1)
+	assert.Equal(suite.T(), "Left Question", saved.Questions[0].Question)
+}
+
+func (suite *StoreTestSuite) TestUpdate_KeepsQuestionIDs() {
+	polcompass := suite.create("Store Compass", "Store Description")
+	kept, removed := polcompass.Questions[0], polcompass.Questions[1]
+
+	polcompass.Questions = []Question{
+		{Question: kept.Question, Affects: "Social", Direction: -1},
+		{Question: "New Question", Affects: "Economic", Direction: 1},
+	}
+	suite.Require().NoError(suite.store.Update(testCtx, &polcompass, nil))
+
+	saved, err := suite.store.Get(testCtx, polcompass.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(saved.Questions, 2)
+	ids := map[string]uint{}
+	for _, q := range saved.Questions {
+		ids[q.Question] = q.ID
+	}
+	assert.Equal(suite.T(), kept.ID, ids[kept.Question])
+	assert.NotContains(suite.T(), ids, removed.Question)
+	assert.NotEqual(suite.T(), removed.ID, ids["New Question"])
+
+	for _, q := range saved.Questions {
+		if q.ID == kept.ID {
+			assert.Equal(suite.T(), "Social", q.Affects)
+		}
+	}
+}
+
+func (suite *StoreTestSuite) TestDeleteAndRestore() {
+	polcompass := suite.create("Store Compass", "Store Description")
+
+	suite.Require().NoError(suite.store.Delete(testCtx, polcompass.ID))
+
+	_, err := suite.store.Get(testCtx, polcompass.ID)
+	assert.Equal(suite.T(), "compass_deleted", suite.errorCode(err))
+
+	err = suite.store.Delete(testCtx, polcompass.ID)
+	assert.Equal(suite.T(), "compass_deleted", suite.errorCode(err))
+
+	err = suite.store.Delete(testCtx, 999)
+	assert.Equal(suite.T(), "compass_not_found", suite.errorCode(err))
+
+	restored, err := suite.store.Restore(testCtx, polcompass.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "Store Compass", restored.Name)
+	assert.Len(suite.T(), restored.Questions, 2)
+
+	_, err = suite.store.Restore(testCtx, polcompass.ID)
+	assert.Equal(suite.T(), "compass_not_found", suite.errorCode(err))
+}
+
+func (suite *StoreTestSuite) TestUpdate_Deleted() {
+	polcompass := suite.create("Store Compass", "Store Description")
+	suite.Require().NoError(suite.store.Delete(testCtx, polcompass.ID))
+
+	polcompass.Name = "Edited"
+	err := suite.store.Update(testCtx, &polcompass, nil)
+	assert.Equal(suite.T(), "compass_not_found", suite.errorCode(err))
+
+	_, err = suite.store.Get(testCtx, polcompass.ID)
+	assert.Equal(suite.T(), "compass_deleted", suite.errorCode(err))
+
+	restored, err := suite.store.Restore(testCtx, polcompass.ID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), "Store Compass", restored.Name)
+}
+
+func (suite *StoreTestSuite) TestListSummaries() {
+	for i := 0; i < 5; i++ {
+		suite.create(fmt.Sprintf("Compass %d", i), "Listed")
+	}
+	suite.create("Unlisted", "")
+	deleted := suite.create("Deleted", "Listed")
+	suite.Require().NoError(suite.store.Delete(testCtx, deleted.ID))
+
+	summaries, count, err := suite.store.ListSummaries(testCtx, 3, 2)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(5), count)
+	suite.Require().Len(summaries, 1)
+	assert.Equal(suite.T(), "Compass 4", summaries[0].Name)
+
+	summaries, _, err = suite.store.ListSummaries(testCtx, 4, 2)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), summaries)
+}
+
+func (suite *StoreTestSuite) TestListSummaries_Empty() {
+	summaries, count, err := suite.store.ListSummaries(testCtx, 1, 10)
+	suite.Require().NoError(err)
+	assert.Zero(suite.T(), count)
+	// rendered as [] rather than null
+	assert.NotNil(suite.T(), summaries)
+	assert.Empty(suite.T(), summaries)
+}
+
+func (suite *StoreTestSuite) TestResponses() {
+	polcompass := suite.create("Store Compass", "Store Description")
+
+	questionID := polcompass.Questions[0].ID
+	response := Response{
+		PublicID:     "0123456789abcdef0123456789abcdef",
+		PolcompassID: polcompass.ID,
+		Scores:       []AxisScore{{Name: "Economic", Score: 0.5}},
+		Answers:      []Answer{{QuestionID: &questionID, Value: 1}},
+	}
+	suite.Require().NoError(suite.store.CreateResponse(testCtx, &response))
+
+	saved, err := suite.store.GetResponse(testCtx, response.PublicID)
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), polcompass.ID, saved.PolcompassID)
+	assert.Equal(suite.T(), []AxisScore{{Name: "Economic", Score: 0.5}}, saved.Scores)
+	suite.Require().Len(saved.Answers, 1)
+	assert.Equal(suite.T(), questionID, *saved.Answers[0].QuestionID)
+
+	_, err = suite.store.GetResponse(testCtx, "unknown")
+	assert.Equal(suite.T(), "response_not_found", suite.errorCode(err))
+}
+
+func (suite *StoreTestSuite) TestReferences() {
+	polcompass := suite.create("Store Compass", "Store Description")
+
+	reference := ReferencePoint{PolcompassID: polcompass.ID, Name: "Party", Field1: 0.5, Scores: []AxisScore{{Name: "Economic", Score: 0.5}}}
+	suite.Require().NoError(suite.store.CreateReference(testCtx, &reference))
+	assert.NotZero(suite.T(), reference.ID)
+
+	reference.Name = "Renamed Party"
+	suite.Require().NoError(suite.store.UpdateReference(testCtx, &reference))
+
+	polcompass.Name = "Renamed"
+	suite.Require().NoError(suite.store.Update(testCtx, &polcompass, nil))
+
+	saved, err := suite.store.Get(testCtx, polcompass.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(saved.References, 1)
+	assert.Equal(suite.T(), "Renamed Party", saved.References[0].Name)
+	// saving the polcompass lines the scores up with its axes
+	assert.Equal(suite.T(), []AxisScore{{Name: "Economic", Score: 0.5}, {Name: "Social", Score: 0}}, saved.References[0].Scores)
+
+	err = suite.store.DeleteReference(testCtx, polcompass.ID+1, reference.ID)
+	assert.Equal(suite.T(), "reference_not_found", suite.errorCode(err))
+
+	suite.Require().NoError(suite.store.DeleteReference(testCtx, polcompass.ID, reference.ID))
+	saved, err = suite.store.Get(testCtx, polcompass.ID)
+	suite.Require().NoError(err)
+	assert.Empty(suite.T(), saved.References)
+
+	err = suite.store.UpdateReference(testCtx, &reference)
+	assert.Equal(suite.T(), "reference_not_found", suite.errorCode(err))
+}
+
+func (suite *StoreTestSuite) TestReferenceAnswersFollowQuestions() {
+	polcompass := suite.create("Store Compass", "Store Description")
+	kept, removed := polcompass.Questions[0], polcompass.Questions[1]
+
+	reference := ReferencePoint{PolcompassID: polcompass.ID, Name: "Party", Answers: []ReferenceAnswer{
+		{QuestionID: kept.ID, Value: 2},
+		{QuestionID: removed.ID, Value: -1},
+	}}
+	suite.Require().NoError(suite.store.CreateReference(testCtx, &reference))
+
+	polcompass.Questions = []Question{{Question: kept.Question, Affects: "Economic", Direction: 1}}
+	suite.Require().NoError(suite.store.Update(testCtx, &polcompass, nil))
+
+	saved, err := suite.store.Get(testCtx, polcompass.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(saved.References, 1)
+	suite.Require().Len(saved.References[0].Answers, 1)
+	assert.Equal(suite.T(), kept.ID, saved.References[0].Answers[0].QuestionID)
+	assert.Equal(suite.T(), 2, saved.References[0].Answers[0].Value)
+	// scored again on the questions left
+	assert.Equal(suite.T(), 1.0, saved.References[0].Field1)
+	assert.Equal(suite.T(), 0.0, saved.References[0].Field2)
+}
+
+func TestGormStoreSuite(t *testing.T) {
+	for _, database := range testDatabases() {
+		t.Run(database.name, func(t *testing.T) {
+			suite.Run(t, &StoreTestSuite{newStore: func() (CompassStore, func()) {
+				db := openTestDB(t, database)
+				return models.NewGormStore(db), func() {
+					sqlDB, _ := db.DB()
+					sqlDB.Close()
+				}
+			}})
+		})
+	}
+}
+
+func TestMemoryStoreSuite(t *testing.T) {
+	suite.Run(t, &StoreTestSuite{newStore: func() (CompassStore, func()) {
+		return models.NewMemoryStore(), func() {}
+	}})
+}
+
+func TestMemoryStore_Handlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := models.NewPolCompassController(models.NewMemoryStore())
+	router := gin.New()
+	router.POST("/polcompass", controller.POST)
+	router.GET("/polcompass/:id", controller.GET)
+	router.POST("/polcompass/:id/score", controller.Score)
+
+	body, _ := json.Marshal(PolCompassReq{
+		Field1Name: "Economic",
+		Field2Name: "Social",
+		Name:       "Memory Compass",
+		Questions: []Question{
+			{Question: "Economic Question", Affects: "Economic", Direction: 1},
+			{Question: "Social Question", Affects: "Social", Direction: -1},
+		},
+	})
+	req, _ := http.NewRequest("POST", "/polcompass", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var created Polcompass
+	json.Unmarshal(w.Body.Bytes(), &created)
+	assert.Equal(t, 1, created.Field1QuestionQty)
+	if !assert.Len(t, created.Questions, 2) {
+		return
+	}
+
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/polcompass/%d", created.ID), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	body, _ = json.Marshal(ScoreReq{Answers: []AnswerReq{
+		{QuestionID: created.Questions[0].ID, Value: 2},
+		{QuestionID: created.Questions[1].ID, Value: 2},
+	}})
+	req, _ = http.NewRequest("POST", fmt.Sprintf("/polcompass/%d/score", created.ID), bytes.NewBuffer(body))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var score ScoreResponse
+	json.Unmarshal(w.Body.Bytes(), &score)
+	assert.Equal(t, 1.0, score.Field1)
+	assert.Equal(t, -1.0, score.Field2)
+
+	req, _ = http.NewRequest("GET", "/polcompass/999", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
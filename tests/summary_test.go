@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"polcompass/backend/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type SummaryTestSuite struct {
+	suite.Suite
+	database   testDatabase
+	DB         *gorm.DB
+	controller *PolCompassController
+	router     *gin.Engine
+}
+
+func (suite *SummaryTestSuite) SetupSuite() {
+	gin.SetMode(gin.TestMode)
+}
+
+func (suite *SummaryTestSuite) SetupTest() {
+	suite.DB = openTestDB(suite.T(), suite.database)
+
+	suite.controller = models.NewPolCompassController(models.NewGormStore(suite.DB))
+	suite.router = gin.New()
+
+	suite.router.GET("/summary", suite.controller.Summary)
+
+	for i := 0; i < 5; i++ {
+		suite.DB.Create(&Polcompass{Name: fmt.Sprintf("Compass %d", i), Description: "Listed"})
+	}
+	suite.DB.Create(&Polcompass{Name: "Unlisted"})
+}
+
+func (suite *SummaryTestSuite) get(url string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *SummaryTestSuite) TestSummary_Pages() {
+	w := suite.get("/summary?perPage=2&page=3")
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var response SummaryResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(suite.T(), 3, response.NumberOfPages)
+	suite.Require().Len(response.Summaries, 1)
+	assert.Equal(suite.T(), "Compass 4", response.Summaries[0].Name)
+}
+
+func (suite *SummaryTestSuite) TestSummary_Errors() {
+	cases := map[string]string{
+		"/summary":                  "missing_parameter",
+		"/summary?perPage=abc":      "invalid_parameter",
+		"/summary?perPage=0":        "invalid_parameter",
+		"/summary?perPage=2&page=x": "invalid_parameter",
+		"/summary?perPage=2&page=0": "invalid_parameter",
+	}
+
+	for url, code := range cases {
+		w := suite.get(url)
+		assert.Equal(suite.T(), http.StatusBadRequest, w.Code, url)
+		assert.Equal(suite.T(), "application/problem+json", w.Header().Get("Content-Type"), url)
+
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(suite.T(), code, response["code"], url)
+	}
+}
+
+func TestSummarySuite(t *testing.T) {
+	for _, database := range testDatabases() {
+		t.Run(database.name, func(t *testing.T) {
+			suite.Run(t, &SummaryTestSuite{database: database})
+		})
+	}
+}